@@ -10,19 +10,31 @@ import (
 	"strings"
 
 	"github.com/jellydn/devlog/internal/logger"
+	"github.com/jellydn/devlog/internal/logrotate"
 	"github.com/jellydn/devlog/internal/natmsg"
+	"github.com/jellydn/devlog/internal/redact"
 )
 
 const usage = `devlog-host - Native messaging host for browser console logs
 
 Usage:
-  devlog-host <log-file-path> [log-levels...]
+  devlog-host [options] <log-file-path> [log-levels...]
 
 Arguments:
   log-file-path   Path to the log file (required)
   log-levels      Space-separated list of log levels to capture
                   (e.g., log warn error). If not specified, all levels are captured.
 
+Options:
+  --format <format>         Log format: text (default), jsonl or logfmt
+  --template <template>     Go text/template for each text line
+  --redact-preset <name>    Mask a built-in kind of secret (bearer, jwt, aws,
+                            url-credentials, email); repeatable
+  --redact-pattern <regex>  Mask matches of a regular expression; repeatable
+  --max-file-size <bytes>   Rotate the log at this size (log.1, log.2, ...)
+  --max-files <n>           Rotated files kept (default 5)
+  --compress                Gzip rotated files
+
 Examples:
   devlog-host ./logs/browser.log
   devlog-host ./logs/browser.log log warn error
@@ -41,6 +53,24 @@ func main() {
 // run is the testable entry point for the native messaging host.
 // args are command-line arguments after the program name.
 func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	redactor, args, err := redact.ParseArgs(args)
+	if err != nil {
+		fmt.Fprint(stderr, usage)
+		return err
+	}
+	rotation, args, err := logrotate.ParseArgs(args)
+	if err != nil {
+		fmt.Fprint(stderr, usage)
+		return err
+	}
+	format, args, err := logger.ParseArgs(args)
+	if err == nil {
+		err = logger.ValidateFormat(format)
+	}
+	if err != nil {
+		fmt.Fprint(stderr, usage)
+		return err
+	}
 	if len(args) < 1 {
 		fmt.Fprint(stderr, usage)
 		return fmt.Errorf("log file path is required")
@@ -60,6 +90,12 @@ func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
 		return fmt.Errorf("Error: failed to create logger: %v\n", err)
 	}
 	defer log.Close()
+	log.SetRedactor(redactor)
+	log.SetRotation(rotation)
+	log.OnRotateError(func(err error) { fmt.Fprintf(stderr, "Error rotating log: %v\n", err) })
+	if err := log.SetFormat(format); err != nil {
+		return fmt.Errorf("Error: failed to set log format: %v\n", err)
+	}
 
 	return processMessages(log, natmsg.NewHostWithStreams(stdin, stdout), stderr)
 }
@@ -117,6 +117,55 @@ func TestRun_ProcessesMessagesAndFiltersLevels(t *testing.T) {
 	}
 }
 
+func TestRun_RedactsSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "browser.log")
+
+	var stdin bytes.Buffer
+	stdin.Write(encodeNativeMessage(t, sampleMessage("error", "login failed for jane@example.com, key=abc123")))
+
+	args := []string{"--redact-preset", "email", logPath, "--redact-pattern", `key=(\w+)`, "error"}
+	if err := run(args, &stdin, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "login failed for [REDACTED], key=[REDACTED]") {
+		t.Errorf("log = %q, want secrets redacted", content)
+	}
+}
+
+func TestRun_JSONLFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "browser.jsonl")
+
+	var stdin bytes.Buffer
+	stdin.Write(encodeNativeMessage(t, sampleMessage("error", "multi\nline")))
+
+	if err := run([]string{"--format", "jsonl", logPath}, &stdin, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("run() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	var msg natmsg.Message
+	if err := json.Unmarshal(content, &msg); err != nil {
+		t.Fatalf("log is not a JSON line: %v (%q)", err, content)
+	}
+	if msg.Message != "multi\nline" || msg.URL != "http://localhost:3000/" || msg.Level != "error" {
+		t.Errorf("decoded message = %+v", msg)
+	}
+
+	if err := run([]string{"--format", "xml", logPath}, bytes.NewReader(nil), &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Error("run() with an unknown format: want error")
+	}
+}
+
 func TestRun_EOFExitsCleanly(t *testing.T) {
 	tmpDir := t.TempDir()
 	logPath := filepath.Join(tmpDir, "browser.log")
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/jellydn/devlog/internal/config"
 	"github.com/jellydn/devlog/internal/manifest"
 	"github.com/jellydn/devlog/internal/tmux"
 )
@@ -36,11 +37,12 @@ func (manifestAdapter) GetFirefoxNativeMessagingDirs() []string {
 	return manifest.GetFirefoxNativeMessagingDirs()
 }
 
-// tmuxAdapter adapts tmux.Runner to browsersession.SessionChecker.
-// SessionExists ignores the name argument and checks the runner's configured session,
-// matching the previous helpers.go behavior of tmux.NewRunner(otherSession).SessionExists().
+// tmuxSessionChecker adapts tmux.Runner to browsersession.SessionChecker.
+// The other session's config is unknown, so it is looked for on that
+// session's default devlog server and on the user's default server.
 type tmuxSessionChecker struct{}
 
 func (tmuxSessionChecker) SessionExists(name string) bool {
-	return tmux.NewRunner(name).SessionExists()
+	server := tmux.Server{Socket: config.SessionSocketName(name)}
+	return tmux.NewServerRunner(name, server).SessionExists() || tmux.NewRunner(name).SessionExists()
 }
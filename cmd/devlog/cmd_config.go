@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+func cmdConfig(cfg *config.Config, configPath string, args []string) error {
+	fmt.Println("Config files (merge order):")
+	for _, file := range cfg.Files {
+		fmt.Printf("  %s\n", file)
+	}
+
+	fmt.Println("\nValues:")
+	for _, src := range cfg.Sources {
+		fmt.Printf("  %s = %s  (%s)\n", src.Key, src.Value, displayConfigPath(cfg.Dir, src.File))
+	}
+	return nil
+}
+
+// displayConfigPath shortens file relative to the config directory when possible.
+func displayConfigPath(dir, file string) string {
+	if dir == "" {
+		return file
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(dir, absFile)
+	if err != nil {
+		return file
+	}
+	return rel
+}
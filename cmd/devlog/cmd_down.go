@@ -8,28 +8,78 @@ import (
 	"github.com/jellydn/devlog/internal/tmux"
 )
 
-func cmdDown(cfg *config.Config, args []string) error {
-	fmt.Printf("Stopping devlog session '%s'...\n", cfg.Tmux.Session)
+func cmdDown(cfg *config.Config, configPath string, args []string) error {
+	profile, err := parseProfileFlag(args)
+	if err != nil {
+		return err
+	}
+
+	out.Printf("Stopping devlog session '%s'...\n", cfg.Tmux.Session)
 
 	// Create tmux runner
-	runner := tmux.NewRunner(cfg.Tmux.Session)
+	runner := newRunner(cfg)
 	bs := browsersession.New(manifestAdapter{}, tmuxSessionChecker{})
 
 	// Check if session exists
 	if !runner.SessionExists() {
 		bs.Stop(cfg.Tmux.Session)
+		if cfg.TmuxSocket() != "" && tmux.NewRunner(cfg.Tmux.Session).SessionExists() {
+			return fmt.Errorf("tmux session '%s' does not exist on devlog's tmux server, but one runs on your default server; stop it with 'tmux kill-session -t %s' or set tmux.socket: default", cfg.Tmux.Session, cfg.Tmux.Session)
+		}
 		return fmt.Errorf("tmux session '%s' does not exist", cfg.Tmux.Session)
 	}
 
-	// Kill the session
-	if err := runner.KillSession(); err != nil {
+	// Default to the profile the session was started with
+	if profile == "" {
+		profile = runner.GetProfile()
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
 		return err
 	}
+	if cfg.Profile != "" {
+		out.Printf("Profile: %s\n", cfg.Profile)
+	}
+
+	// Read the run directory before the session (and its env) goes away
+	hookRunner := newHookRunner(cfg, runner.GetLogsDir())
+	if err := hookRunner.Run("pre_down", cfg.Hooks.PreDown); err != nil {
+		out.Warnf("%v", err)
+	}
+
+	// Stop pane commands with the configured signal sequence, then kill the
+	// session. A failure here must not keep the browser manifest pointing at
+	// the wrapper or skip post_down, so it is reported at the end.
+	stops, stopErr := runner.StopSession(tmux.StopOptions{
+		Signals: cfg.Shutdown.SignalNames(),
+		Timeout: cfg.Shutdown.TimeoutDuration(),
+	})
+	printPaneStops(stops)
 
 	// Restore native messaging manifest to point to the real binary
 	bs.Stop(cfg.Tmux.Session)
 
-	fmt.Printf("Stopped tmux session '%s'\n", cfg.Tmux.Session)
+	if err := hookRunner.Run("post_down", cfg.Hooks.PostDown); err != nil {
+		out.Warnf("%v", err)
+	}
+
+	if stopErr != nil {
+		return stopErr
+	}
+	out.Printf("Stopped tmux session '%s'\n", cfg.Tmux.Session)
 
 	return nil
 }
+
+// printPaneStops reports panes that did not stop on the first signal.
+func printPaneStops(stops []tmux.PaneStop) {
+	for _, stop := range stops {
+		switch {
+		case stop.Running:
+			out.Warnf("%s was still running after the last signal", stop.Pane)
+		case stop.Forced:
+			out.Warnf("%s did not stop in time; force-killed with SIG%s", stop.Pane, stop.Signal)
+		default:
+			out.Printf("%s stopped after SIG%s\n", stop.Pane, stop.Signal)
+		}
+	}
+}
@@ -9,80 +9,129 @@ import (
 	"github.com/jellydn/devlog/internal/tmux"
 )
 
-func cmdHealthcheck(cfg *config.Config, args []string) error {
-	const maxLabelLen = 22
+func cmdHealthcheck(cfg *config.Config, configPath string, args []string) error {
+	result, err := runHealthchecks()
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("devlog healthcheck")
-	fmt.Println("==================")
-	fmt.Println()
+	if out.JSON() {
+		if err := out.PrintJSON(result); err != nil {
+			return err
+		}
+		if !result.OK {
+			return silentError{fmt.Errorf("healthcheck failed")}
+		}
+		return nil
+	}
 
-	allGood := true
+	printHealthchecks(result)
+	if !result.OK {
+		return fmt.Errorf("healthcheck failed")
+	}
+	return nil
+}
+
+// runHealthchecks checks tmux, the devlog-host binary and the browser
+// extension registration.
+func runHealthchecks() (*HealthcheckOutput, error) {
+	result := &HealthcheckOutput{OK: true}
+	add := func(c CheckResult) {
+		result.Checks = append(result.Checks, c)
+		if !c.OK {
+			result.OK = false
+		}
+	}
 
 	// Check tmux
-	fmt.Printf("%-*s ", maxLabelLen, "tmux:")
-	version, err := tmux.CheckVersion()
-	if err != nil {
-		fmt.Println("✗ NOT FOUND")
-		fmt.Println("  tmux is required to run devlog.")
-		fmt.Println("  Install: https://github.com/tmux/tmux/wiki/Installing")
-		allGood = false
+	if version, err := tmux.CheckVersion(); err != nil {
+		add(CheckResult{
+			Name:   "tmux",
+			Detail: "NOT FOUND",
+			Hint:   "tmux is required to run devlog.\nInstall: https://github.com/tmux/tmux/wiki/Installing",
+		})
 	} else {
-		fmt.Printf("✓ %s\n", version)
+		add(CheckResult{Name: "tmux", OK: true, Detail: version})
 	}
 
 	bs := browsersession.New(manifestAdapter{}, tmuxSessionChecker{})
-	result, err := bs.HealthCheck()
+	health, err := bs.HealthCheck()
 	if err != nil {
-		return fmt.Errorf("browser healthcheck failed: %w", err)
+		return nil, fmt.Errorf("browser healthcheck failed: %w", err)
 	}
 
 	// Check devlog-host binary
-	fmt.Printf("%-*s ", maxLabelLen, "devlog-host binary:")
-	if !result.HostFound {
-		fmt.Println("✗ NOT FOUND")
-		fmt.Println("  devlog-host is required for browser logging.")
-		fmt.Println("  Install: go install github.com/jellydn/devlog/cmd/devlog-host@latest")
-		allGood = false
+	if !health.HostFound {
+		add(CheckResult{
+			Name:   "devlog-host binary",
+			Detail: "NOT FOUND",
+			Hint:   "devlog-host is required for browser logging.\nInstall: go install github.com/jellydn/devlog/cmd/devlog-host@latest",
+		})
 	} else {
-		fmt.Printf("✓ %s\n", result.HostPath)
+		add(CheckResult{Name: "devlog-host binary", OK: true, Detail: health.HostPath})
 	}
 
 	// Check native messaging manifests
-	fmt.Printf("%-*s ", maxLabelLen, "Browser extension:")
-	if len(result.Registered) > 0 {
-		fmt.Printf("✓ Registered for %s\n", strings.Join(result.Registered, ", "))
+	if len(health.Registered) > 0 {
+		add(CheckResult{Name: "Browser extension", OK: true, Detail: "Registered for " + strings.Join(health.Registered, ", ")})
 	} else {
-		fmt.Println("✗ NOT REGISTERED")
-		fmt.Println("  Browser extension is not registered.")
-		fmt.Println("  Register: devlog register --chrome --extension-id <id>")
-		fmt.Println("            devlog register --brave --extension-id <id>")
-		fmt.Println("            devlog register --firefox")
-		allGood = false
+		add(CheckResult{
+			Name:   "Browser extension",
+			Detail: "NOT REGISTERED",
+			Hint: "Browser extension is not registered.\n" +
+				"Register: devlog register --chrome --extension-id <id>\n" +
+				"          devlog register --brave --extension-id <id>\n" +
+				"          devlog register --firefox",
+		})
 	}
 
 	// Check that manifest path targets exist on disk (self-heal when possible)
-	fmt.Printf("%-*s ", maxLabelLen, "Manifest host path:")
-	if result.HostFound && result.RepairedPaths > 0 {
-		fmt.Printf("✓ repaired %d stale path(s)\n", result.RepairedPaths)
+	manifest := CheckResult{Name: "Manifest host path", OK: true}
+	switch {
+	case health.ManifestPaths == 0:
+		manifest.Detail = "none installed"
+	case health.StalePaths > 0:
+		manifest.OK = false
+		manifest.Detail = fmt.Sprintf("%d path(s) missing on disk", health.StalePaths)
+		manifest.Hint = "Run: devlog up  (or re-register) to repair"
+	default:
+		manifest.Detail = fmt.Sprintf("%d path(s) exist", health.ManifestPaths)
 	}
-	if result.ManifestPaths == 0 {
-		// Match prior behavior: when no paths and repair already printed a line,
-		// still report "none installed" on its own line.
-		fmt.Println("○ none installed")
-	} else if result.StalePaths > 0 {
-		fmt.Printf("✗ %d path(s) missing on disk\n", result.StalePaths)
-		fmt.Println("  Run: devlog up  (or re-register) to repair")
-		allGood = false
-	} else {
-		fmt.Printf("✓ %d path(s) exist\n", result.ManifestPaths)
+	if health.HostFound && health.RepairedPaths > 0 {
+		manifest.Detail += fmt.Sprintf(" (repaired %d stale path(s))", health.RepairedPaths)
 	}
+	add(manifest)
 
-	fmt.Println()
-	if allGood {
-		fmt.Println("✓ All checks passed! You're ready to use devlog.")
-		return nil
+	return result, nil
+}
+
+func printHealthchecks(result *HealthcheckOutput) {
+	const maxLabelLen = 22
+
+	out.Println("devlog healthcheck")
+	out.Println("==================")
+	out.Println()
+
+	for _, c := range result.Checks {
+		mark := out.green("✓")
+		switch {
+		case !c.OK:
+			mark = out.red("✗")
+		case c.Detail == "none installed":
+			mark = "○"
+		}
+		out.Printf("%-*s %s %s\n", maxLabelLen, c.Name+":", mark, c.Detail)
+		if c.Hint != "" {
+			for _, line := range strings.Split(c.Hint, "\n") {
+				out.Printf("  %s\n", line)
+			}
+		}
 	}
 
-	fmt.Println("⚠ Some checks failed. Please address the issues above.")
-	return fmt.Errorf("healthcheck failed")
+	out.Println()
+	if result.OK {
+		out.Printf("%s All checks passed! You're ready to use devlog.\n", out.green("✓"))
+		return
+	}
+	out.Printf("%s Some checks failed. Please address the issues above.\n", out.yellow("⚠"))
 }
@@ -8,25 +8,29 @@ import (
 	"github.com/jellydn/devlog/internal/config"
 )
 
-func cmdInit(cfg *config.Config, args []string) error {
-	// Check if devlog.yml already exists
-	configPath := filepath.Join(".", "devlog.yml")
+// cmdInit writes a config template to configPath (the --config/DEVLOG_CONFIG
+// path), or to devlog.yml in the current directory.
+func cmdInit(cfg *config.Config, configPath string, args []string) error {
+	if configPath == "" {
+		configPath = "devlog.yml"
+	}
 	if _, err := os.Stat(configPath); err == nil {
-		return fmt.Errorf("devlog.yml already exists in current directory")
+		return fmt.Errorf("%s already exists", configPath)
 	}
 
-	// Get current directory name for defaults
-	cwd, err := os.Getwd()
+	// Get the config file's directory name for defaults
+	absPath, err := filepath.Abs(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+		return fmt.Errorf("failed to resolve config path: %w", err)
 	}
-	projectName := filepath.Base(cwd)
+	projectDir := filepath.Dir(absPath)
+	projectName := filepath.Base(projectDir)
 
 	// Detect if this is a monorepo by checking for common patterns
 	isMonorepo := false
 	monorepoIndicators := []string{"packages", "apps", "services"}
 	for _, dir := range monorepoIndicators {
-		if _, err := os.Stat(dir); err == nil {
+		if _, err := os.Stat(filepath.Join(projectDir, dir)); err == nil {
 			isMonorepo = true
 			break
 		}
@@ -37,16 +41,20 @@ func cmdInit(cfg *config.Config, args []string) error {
 
 	// Write the file
 	if err := os.WriteFile(configPath, []byte(template), 0644); err != nil {
-		return fmt.Errorf("failed to write devlog.yml: %w", err)
+		return fmt.Errorf("failed to write %s: %w", configPath, err)
 	}
 
-	fmt.Printf("Created devlog.yml in current directory\n")
-	fmt.Printf("Project: %s\n", projectName)
+	out.Printf("Created %s\n", configPath)
+	out.Printf("Project: %s\n", projectName)
 	if isMonorepo {
-		fmt.Printf("Detected monorepo structure\n")
+		out.Printf("Detected monorepo structure\n")
+	}
+	out.Printf("\nEdit %s to customize your configuration, then run:\n", configPath)
+	if configPath == "devlog.yml" {
+		out.Printf("  devlog up\n")
+	} else {
+		out.Printf("  devlog --config %s up\n", configPath)
 	}
-	fmt.Printf("\nEdit devlog.yml to customize your configuration, then run:\n")
-	fmt.Printf("  devlog up\n")
 
 	return nil
 }
@@ -54,7 +62,7 @@ func cmdInit(cfg *config.Config, args []string) error {
 // generateTemplate creates the YAML template content
 func generateTemplate(projectName string, isMonorepo bool) string {
 	if isMonorepo {
-		return fmt.Sprintf(`version: "1.0"
+		return fmt.Sprintf(`version: "%s"
 project: %s
 logs_dir: ./logs
 run_mode: timestamped # timestamped | overwrite
@@ -79,10 +87,10 @@ browser:
     - warn
     - info
     - log
-`, projectName, projectName)
+`, config.CurrentVersion, projectName, projectName)
 	}
 
-	return fmt.Sprintf(`version: "1.0"
+	return fmt.Sprintf(`version: "%s"
 project: %s
 logs_dir: ./logs
 run_mode: timestamped # timestamped | overwrite
@@ -105,5 +113,5 @@ browser:
     - warn
     - info
     - log
-`, projectName, projectName)
+`, config.CurrentVersion, projectName, projectName)
 }
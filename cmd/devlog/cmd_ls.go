@@ -8,45 +8,63 @@ import (
 	"github.com/jellydn/devlog/internal/config"
 )
 
-func cmdLs(cfg *config.Config, args []string) error {
-	logsDir := cfg.LogsDir
+func cmdLs(cfg *config.Config, configPath string, args []string) error {
+	logsDir := cfg.ResolveLogsDir()
+	result := LsOutput{LogsDir: logsDir, RunMode: cfg.RunMode, Runs: []RunEntry{}}
 
 	entries, err := os.ReadDir(logsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			fmt.Printf("No log runs found (logs directory '%s' does not exist)\n", logsDir)
+			if out.JSON() {
+				return out.PrintJSON(result)
+			}
+			out.Printf("No log runs found (logs directory '%s' does not exist)\n", logsDir)
 			return nil
 		}
 		return fmt.Errorf("failed to read logs directory: %w", err)
 	}
-
-	var dirs []os.DirEntry
-	for _, e := range entries {
-		if e.IsDir() {
-			dirs = append(dirs, e)
-		}
-	}
+	result.Exists = true
 
 	if cfg.RunMode == "timestamped" {
-		if len(dirs) == 0 {
-			fmt.Println("No log runs found")
-			return nil
-		}
-		fmt.Printf("Log runs in %s (%d):\n", logsDir, len(dirs))
-		for _, d := range dirs {
-			info, err := d.Info()
-			if err != nil {
-				fmt.Printf("  %s\n", d.Name())
+		for _, e := range entries {
+			if !e.IsDir() {
 				continue
 			}
-			logFiles := countFiles(filepath.Join(logsDir, d.Name()))
-			fmt.Printf("  %s  (%d files, %s)\n", d.Name(), logFiles, info.ModTime().Format("Jan 02 15:04"))
+			run := RunEntry{Name: e.Name(), Path: filepath.Join(logsDir, e.Name())}
+			if info, err := e.Info(); err == nil {
+				run.Modified = info.ModTime()
+				run.Files = countFiles(run.Path)
+			}
+			result.Runs = append(result.Runs, run)
 		}
 	} else {
-		files := countFiles(logsDir)
-		fmt.Printf("Logs directory: %s (%d files)\n", logsDir, files)
+		run := RunEntry{Name: filepath.Base(logsDir), Path: logsDir, Files: countFiles(logsDir)}
+		if info, err := os.Stat(logsDir); err == nil {
+			run.Modified = info.ModTime()
+		}
+		result.Runs = append(result.Runs, run)
+	}
+
+	if out.JSON() {
+		return out.PrintJSON(result)
 	}
 
+	if cfg.RunMode != "timestamped" {
+		out.Printf("Logs directory: %s (%d files)\n", logsDir, result.Runs[0].Files)
+		return nil
+	}
+	if len(result.Runs) == 0 {
+		out.Println("No log runs found")
+		return nil
+	}
+	out.Printf("Log runs in %s (%d):\n", logsDir, len(result.Runs))
+	for _, run := range result.Runs {
+		if run.Modified.IsZero() {
+			out.Printf("  %s\n", run.Name)
+			continue
+		}
+		out.Printf("  %s  (%d files, %s)\n", run.Name, run.Files, run.Modified.Format("Jan 02 15:04"))
+	}
 	return nil
 }
 
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+func cmdMigrate(cfg *config.Config, configPath string, args []string) error {
+	dryRun := false
+	for _, arg := range args {
+		switch {
+		case arg == "--dry-run":
+			dryRun = true
+		case arg == "--help" || arg == "-h":
+			return cmdHelp(nil, "", []string{"migrate"})
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown argument: %s (use --help for usage)", arg)
+		default:
+			configPath = arg
+		}
+	}
+
+	if configPath == "" {
+		return errConfigNotFound
+	}
+
+	migrated, changes, err := config.MigrateFile(configPath, !dryRun)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		out.Printf("%s is already at config version %s\n", configPath, config.CurrentVersion)
+		return nil
+	}
+
+	if dryRun {
+		_, err := os.Stdout.Write(migrated)
+		return err
+	}
+
+	out.Printf("Migrated %s to config version %s:\n", configPath, config.CurrentVersion)
+	for _, change := range changes {
+		out.Printf("  - %s\n", change)
+	}
+	return nil
+}
@@ -7,18 +7,17 @@ import (
 	"runtime"
 
 	"github.com/jellydn/devlog/internal/config"
-	"github.com/jellydn/devlog/internal/tmux"
 )
 
-func cmdOpen(cfg *config.Config, args []string) error {
-	runner := tmux.NewRunner(cfg.Tmux.Session)
+func cmdOpen(cfg *config.Config, configPath string, args []string) error {
+	runner := newRunner(cfg)
 
 	logsDir := ""
 	if runner.SessionExists() {
 		logsDir = runner.GetLogsDir()
 	}
 	if logsDir == "" {
-		logsDir = cfg.LogsDir
+		logsDir = cfg.ResolveLogsDir()
 	}
 
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
@@ -30,7 +29,7 @@ func cmdOpen(cfg *config.Config, args []string) error {
 		return fmt.Errorf("failed to open logs directory: %w", err)
 	}
 
-	fmt.Printf("Opened: %s\n", logsDir)
+	out.Printf("Opened: %s\n", logsDir)
 	return nil
 }
 
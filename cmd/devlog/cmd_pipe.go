@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/logrotate"
+	"github.com/jellydn/devlog/internal/panelog"
+	"github.com/jellydn/devlog/internal/redact"
+)
+
+// cmdPipe copies pane output from stdin to a log file. tmux pipe-pane runs it
+// for panes whose log is processed (see config.PaneConfig.Piped).
+func cmdPipe(cfg *config.Config, configPath string, args []string) error {
+	redactor, args, err := redact.ParseArgs(args)
+	if err != nil {
+		return err
+	}
+	rotation, args, err := logrotate.ParseArgs(args)
+	if err != nil {
+		return err
+	}
+	opts := panelog.Options{Redactor: redactor}
+	var path, rawPath string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--sanitize":
+			opts.Sanitize = true
+		case "--timestamps", "--start", "--raw":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			switch arg {
+			case "--timestamps":
+				opts.Timestamps = args[i]
+			case "--raw":
+				rawPath = args[i]
+			default:
+				start, err := time.Parse(time.RFC3339Nano, args[i])
+				if err != nil {
+					return fmt.Errorf("--start must be an RFC 3339 time: %w", err)
+				}
+				opts.Start = start
+			}
+		default:
+			if path != "" {
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+			path = arg
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("log file path is required")
+	}
+	switch opts.Timestamps {
+	case "", config.TimestampsNone, config.TimestampsRFC3339, config.TimestampsRelative:
+	default:
+		return fmt.Errorf("--timestamps must be 'none', 'rfc3339' or 'relative', got '%s'", opts.Timestamps)
+	}
+	if opts.Start.IsZero() {
+		opts.Start = time.Now()
+	}
+
+	f, err := logrotate.OpenFile(path, rotation)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	// pipe-pane discards stderr; the log is where the error gets seen
+	f.OnError(func(err error) { fmt.Fprintf(f, "[devlog] %v\n", err) })
+	if rawPath != "" {
+		raw, err := logrotate.OpenFile(rawPath, rotation)
+		if err != nil {
+			return err
+		}
+		defer raw.Close()
+		raw.OnError(func(err error) { fmt.Fprintf(f, "[devlog] %s: %v\n", rawPath, err) })
+		opts.Raw = raw
+	}
+	return panelog.Copy(f, os.Stdin, opts)
+}
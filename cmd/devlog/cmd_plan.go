@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jellydn/devlog/internal/browsersession"
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/logrotate"
+	"github.com/jellydn/devlog/internal/shellescape"
+	"github.com/jellydn/devlog/internal/tmux"
+)
+
+// cmdPlan prints what `devlog up` would do without changing anything: old
+// runs it would remove, hooks, every tmux command and the browser wrapper
+// and manifest changes. `devlog up --dry-run` runs it too.
+func cmdPlan(cfg *config.Config, configPath string, args []string) error {
+	profile, err := parseProfileFlag(args)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
+		return err
+	}
+
+	runner := newRunner(cfg)
+	if runner.SessionExists() {
+		out.Warnf("tmux session '%s' already exists; 'devlog up' would fail until you run 'devlog down'", cfg.Tmux.Session)
+	}
+
+	baseLogsDir := cfg.ResolveLogsDir()
+	runDir := tmux.RunDir(baseLogsDir, cfg.RunMode, time.Now())
+	plan, err := runner.Plan(tmux.SessionConfig{
+		Session: cfg.Tmux.Session,
+		LogsDir: baseLogsDir,
+		RunMode: cfg.RunMode,
+		RunDir:  runDir,
+		Profile: cfg.Profile,
+		Devlog:  devlogExecutable(),
+		Redact:  cfg.Redact,
+		Windows: cfg.Tmux.Windows,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := out.stdout
+	fmt.Fprintf(w, "# devlog up plan for session '%s'", cfg.Tmux.Session)
+	if cfg.Profile != "" {
+		fmt.Fprintf(w, " (profile %s)", cfg.Profile)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "# pane ids are shown as %<window>.<pane> until tmux assigns them")
+
+	if cfg.RunMode == "timestamped" {
+		policy := logrotate.Policy{MaxRuns: cfg.MaxRuns, RetentionDays: cfg.RetentionDays}
+		if result, err := logrotate.Cleanup(baseLogsDir, policy, true); err != nil {
+			out.Warnf("failed to check old runs: %v", err)
+		} else if result != nil {
+			for _, dir := range result.Removed {
+				fmt.Fprintf(w, "rm -r %s  # old run\n", dir)
+			}
+		}
+	}
+	printHookPlan(w, "pre_up", cfg.Hooks.PreUp)
+	plan.Print(w)
+
+	if len(cfg.Browser.URLs) > 0 && cfg.Browser.File != "" {
+		fmt.Fprintln(w, "# browser logging")
+		bs := browsersession.New(manifestAdapter{}, tmuxSessionChecker{})
+		lines, err := bs.PlanStart(cfg.Tmux.Session, config.RunFilePath(runDir, cfg.Browser.File), browserHostArgs(cfg))
+		if err != nil {
+			fmt.Fprintf(w, "# browser logging would fail: %v\n", err)
+		}
+		for _, line := range lines {
+			fmt.Fprintf(w, "# %s\n", line)
+		}
+	}
+	printHookPlan(w, "post_up", cfg.Hooks.PostUp)
+	return nil
+}
+
+func printHookPlan(w io.Writer, stage string, commands []string) {
+	for _, command := range commands {
+		fmt.Fprintf(w, "sh -c %s  # %s hook\n", shellescape.Quote(command), stage)
+	}
+}
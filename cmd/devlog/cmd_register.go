@@ -7,7 +7,7 @@ import (
 	"github.com/jellydn/devlog/internal/manifest"
 )
 
-func cmdRegister(cfg *config.Config, args []string) error {
+func cmdRegister(cfg *config.Config, configPath string, args []string) error {
 	hostPath, err := manifest.FindDevlogHostBinary()
 	if err != nil {
 		return fmt.Errorf("failed to find devlog-host binary: %w", err)
@@ -35,24 +35,7 @@ func cmdRegister(cfg *config.Config, args []string) error {
 				return fmt.Errorf("--extension-id requires a value")
 			}
 		case "--help", "-h":
-			fmt.Print(`Usage: devlog register [options]
-
-Register native messaging host for browser logging.
-
-Options:
-  --chrome         Register for Google Chrome
-  --brave          Register for Brave Browser
-  --firefox        Register for Mozilla Firefox
-  --extension-id   Chrome/Brave extension ID (required for --chrome or --brave)
-  --help, -h       Show this help message
-
-Examples:
-  devlog register --chrome --extension-id abcdefghijklmnopqrstuvwxyz123456
-  devlog register --brave --extension-id abcdefghijklmnopqrstuvwxyz123456
-  devlog register --firefox
-  devlog register --chrome --brave --extension-id abcdefghijklmnopqrstuvwxyz123456
-`)
-			return nil
+			return cmdHelp(nil, "", []string{"register"})
 		default:
 			return fmt.Errorf("unknown argument: %s (use --help for usage)", arg)
 		}
@@ -69,28 +52,28 @@ Examples:
 		return fmt.Errorf("--extension-id is required when registering for Chrome or Brave")
 	}
 
-	fmt.Printf("devlog-host binary: %s\n", hostPath)
+	out.Printf("devlog-host binary: %s\n", hostPath)
 
 	if installChrome {
-		fmt.Printf("Registering for Chrome...\n")
+		out.Printf("Registering for Chrome...\n")
 		if err := manifest.InstallChromeManifest(hostPath, extensionID); err != nil {
 			return fmt.Errorf("failed to register Chrome manifest: %w", err)
 		}
 		dir := manifest.GetChromeNativeMessagingDir()
-		fmt.Printf("  Installed to: %s\n", dir)
+		out.Printf("  Installed to: %s\n", dir)
 	}
 
 	if installBrave {
-		fmt.Printf("Registering for Brave...\n")
+		out.Printf("Registering for Brave...\n")
 		if err := manifest.InstallBraveManifest(hostPath, extensionID); err != nil {
 			return fmt.Errorf("failed to register Brave manifest: %w", err)
 		}
 		dir := manifest.GetBraveNativeMessagingDir()
-		fmt.Printf("  Installed to: %s\n", dir)
+		out.Printf("  Installed to: %s\n", dir)
 	}
 
 	if installFirefox {
-		fmt.Printf("Registering for Firefox...\n")
+		out.Printf("Registering for Firefox...\n")
 		// Use extension ID if provided, otherwise use default
 		firefoxExtID := extensionID
 		if firefoxExtID == "" {
@@ -100,15 +83,15 @@ Examples:
 			return fmt.Errorf("failed to register Firefox manifest: %w", err)
 		}
 		dirs := manifest.GetFirefoxNativeMessagingDirs()
-		fmt.Printf("  Installed to:\n")
+		out.Printf("  Installed to:\n")
 		for _, dir := range dirs {
-			fmt.Printf("    - %s\n", dir)
+			out.Printf("    - %s\n", dir)
 		}
 		if extensionID != "" {
-			fmt.Printf("  Extension ID: %s\n", extensionID)
+			out.Printf("  Extension ID: %s\n", extensionID)
 		}
 	}
 
-	fmt.Println("Registration complete!")
+	out.Println("Registration complete!")
 	return nil
 }
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/tmux"
+)
+
+// cmdRun runs a pane's command. devlog up starts every pane with it (tmux
+// respawn-pane), see tmux.PaneCommand.
+func cmdRun(cfg *config.Config, configPath string, args []string) error {
+	return tmux.RunPane(args)
+}
@@ -9,92 +9,198 @@ import (
 	"github.com/jellydn/devlog/internal/tmux"
 )
 
-func cmdStatus(cfg *config.Config, args []string) error {
-	fmt.Printf("Project: %s\n", cfg.Project)
-	fmt.Printf("Session: %s\n", cfg.Tmux.Session)
-	fmt.Printf("Run mode: %s\n", cfg.RunMode)
+func cmdStatus(cfg *config.Config, configPath string, args []string) error {
+	profile, err := parseProfileFlag(args)
+	if err != nil {
+		return err
+	}
 
 	// Create tmux runner
-	runner := tmux.NewRunner(cfg.Tmux.Session)
+	runner := newRunner(cfg)
+	running := runner.SessionExists()
 
-	// Check session status
-	if !runner.SessionExists() {
-		fmt.Println("\nStatus: Not running")
-		return nil
+	// Default to the profile the running session was started with
+	if profile == "" && running {
+		profile = runner.GetProfile()
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
+		return err
+	}
+
+	status, err := collectStatus(cfg, runner, running)
+	if err != nil {
+		return err
+	}
+	if out.JSON() {
+		return out.PrintJSON(status)
+	}
+	printStatus(status)
+	return nil
+}
+
+// collectStatus gathers the session, pane, log file and browser state.
+func collectStatus(cfg *config.Config, runner *tmux.Runner, running bool) (*StatusOutput, error) {
+	status := &StatusOutput{
+		Project: cfg.Project,
+		Session: cfg.Tmux.Session,
+		RunMode: cfg.RunMode,
+		Profile: cfg.Profile,
+		Running: running,
+	}
+	if !running {
+		return status, nil
 	}
 
 	// Resolve logs directory from the running session
 	logsDir := resolveStatusLogsDir(runner.GetLogsDir(), cfg)
-	fmt.Printf("Logs directory: %s\n", logsDir)
+	status.LogsDir = logsDir
 
-	// Get session info
 	info, err := runner.GetSessionInfo()
 	if err != nil {
-		return fmt.Errorf("failed to get session info: %w", err)
+		return nil, fmt.Errorf("failed to get session info: %w", err)
+	}
+	events, err := tmux.LastPaneEvents(logsDir, info.Created)
+	if err != nil {
+		out.Warnf("failed to read pane events: %v", err)
 	}
-
-	fmt.Println("\nStatus: Running")
-	fmt.Printf("Windows (%d):\n", len(info.Windows))
 	for _, w := range info.Windows {
-		fmt.Printf("  [%d] %s (%d panes)\n", w.Index, w.Name, w.PaneCount)
+		window := WindowStatus{Index: w.Index, Name: w.Name, Panes: []PaneStatus{}}
 		for _, p := range w.Panes {
-			fmt.Printf("      pane %s: %s\n", p.ID, p.Command)
+			pane := PaneStatus{ID: p.ID, Index: p.Index, Name: p.Name, Command: p.Command}
+			if e, ok := events[p.ID]; ok {
+				pane.setEvent(e)
+			}
+			window.Panes = append(window.Panes, pane)
 		}
+		status.Windows = append(status.Windows, window)
 	}
 
-	// Show log files
-	fmt.Println("\nLog files:")
 	for _, w := range cfg.Tmux.Windows {
 		for _, p := range w.Panes {
 			if p.Log != "" {
-				logPath := filepath.Join(logsDir, p.Log)
-				status := "missing"
-				if fi, err := os.Stat(logPath); err == nil {
-					status = fmt.Sprintf("%d bytes", fi.Size())
-				}
-				fmt.Printf("  %s (%s)\n", logPath, status)
+				status.LogFiles = append(status.LogFiles, logFileStatus(config.RunFilePath(logsDir, p.Log)))
 			}
 		}
 	}
 
-	// Show browser logging status
-	fmt.Println("\nBrowser logging:")
-	if len(cfg.Browser.URLs) > 0 {
-		fmt.Printf("  Status: enabled\n")
-		fmt.Printf("  URLs monitored (%d):\n", len(cfg.Browser.URLs))
-		for _, url := range cfg.Browser.URLs {
-			fmt.Printf("    - %s\n", url)
-		}
-		if cfg.Browser.File != "" {
-			browserLogPath := filepath.Join(logsDir, cfg.Browser.File)
-			status := "missing"
-			if fi, err := os.Stat(browserLogPath); err == nil {
-				status = fmt.Sprintf("%d bytes", fi.Size())
+	browser := &BrowserStatus{Enabled: len(cfg.Browser.URLs) > 0, URLs: cfg.Browser.URLs, Levels: cfg.Browser.Levels}
+	if browser.URLs == nil {
+		browser.URLs = []string{}
+	}
+	if browser.Enabled && cfg.Browser.File != "" {
+		f := logFileStatus(config.RunFilePath(logsDir, cfg.Browser.File))
+		browser.LogFile = &f
+	}
+	status.Browser = browser
+	return status, nil
+}
+
+// setEvent records the pane's latest start or exit event.
+func (p *PaneStatus) setEvent(e tmux.PaneEvent) {
+	t := e.Time
+	if e.Event == tmux.EventExit {
+		p.ExitedAt, p.ExitCode, p.Signal = &t, e.Code, e.Signal
+	} else {
+		p.StartedAt = &t
+	}
+}
+
+// state describes what the pane is doing: its current command, or how its
+// command ended, e.g. "exited 137 at 14:02".
+func (p PaneStatus) state() string {
+	if p.ExitedAt == nil || p.ExitCode == nil {
+		return p.Command
+	}
+	state := fmt.Sprintf("exited %d at %s", *p.ExitCode, p.ExitedAt.Local().Format("15:04"))
+	if p.Signal > 0 {
+		state += fmt.Sprintf(" (signal %d)", p.Signal)
+	}
+	if *p.ExitCode != 0 {
+		return out.red(state)
+	}
+	return state
+}
+
+func logFileStatus(path string) LogFileStatus {
+	f := LogFileStatus{Path: path}
+	if fi, err := os.Stat(path); err == nil {
+		f.Exists = true
+		f.Size = fi.Size()
+	}
+	return f
+}
+
+func (f LogFileStatus) String() string {
+	if !f.Exists {
+		return fmt.Sprintf("%s (missing)", f.Path)
+	}
+	return fmt.Sprintf("%s (%d bytes)", f.Path, f.Size)
+}
+
+func printStatus(status *StatusOutput) {
+	out.Printf("Project: %s\n", status.Project)
+	out.Printf("Session: %s\n", status.Session)
+	out.Printf("Run mode: %s\n", status.RunMode)
+	if status.Profile != "" {
+		out.Printf("Profile: %s\n", status.Profile)
+	}
+
+	if !status.Running {
+		out.Printf("\nStatus: %s\n", out.yellow("Not running"))
+		return
+	}
+	out.Printf("Logs directory: %s\n", status.LogsDir)
+
+	out.Printf("\nStatus: %s\n", out.green("Running"))
+	out.Printf("Windows (%d):\n", len(status.Windows))
+	for _, w := range status.Windows {
+		out.Printf("  [%d] %s (%d panes)\n", w.Index, w.Name, len(w.Panes))
+		for _, p := range w.Panes {
+			if p.Name != "" {
+				out.Printf("      pane %s (%s): %s\n", p.ID, p.Name, p.state())
+			} else {
+				out.Printf("      pane %s: %s\n", p.ID, p.state())
 			}
-			fmt.Printf("  Log file: %s (%s)\n", browserLogPath, status)
 		}
-		if len(cfg.Browser.Levels) > 0 {
-			fmt.Printf("  Levels: %v\n", cfg.Browser.Levels)
-		}
-	} else {
-		fmt.Printf("  Status: disabled (no URLs configured)\n")
 	}
 
-	return nil
+	out.Println("\nLog files:")
+	for _, f := range status.LogFiles {
+		out.Printf("  %s\n", f)
+	}
+
+	out.Println("\nBrowser logging:")
+	browser := status.Browser
+	if !browser.Enabled {
+		out.Printf("  Status: disabled (no URLs configured)\n")
+		return
+	}
+	out.Printf("  Status: enabled\n")
+	out.Printf("  URLs monitored (%d):\n", len(browser.URLs))
+	for _, url := range browser.URLs {
+		out.Printf("    - %s\n", url)
+	}
+	if browser.LogFile != nil {
+		out.Printf("  Log file: %s\n", browser.LogFile)
+	}
+	if len(browser.Levels) > 0 {
+		out.Printf("  Levels: %v\n", browser.Levels)
+	}
 }
 
 func resolveStatusLogsDir(runningLogsDir string, cfg *config.Config) string {
 	if runningLogsDir != "" {
 		return runningLogsDir
 	}
+	baseLogsDir := cfg.ResolveLogsDir()
 	if cfg.RunMode != "timestamped" {
-		return cfg.LogsDir
+		return baseLogsDir
 	}
-	latestRun := latestRunDir(cfg.LogsDir)
+	latestRun := latestRunDir(baseLogsDir)
 	if latestRun != "" {
 		return latestRun
 	}
-	return cfg.LogsDir
+	return baseLogsDir
 }
 
 func latestRunDir(baseLogsDir string) string {
@@ -1,72 +1,210 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"time"
 
 	"github.com/jellydn/devlog/internal/browsersession"
 	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/hooks"
 	"github.com/jellydn/devlog/internal/logrotate"
 	"github.com/jellydn/devlog/internal/tmux"
 )
 
-func cmdUp(cfg *config.Config, args []string) error {
-	fmt.Printf("Starting devlog session '%s'...\n", cfg.Tmux.Session)
+func cmdUp(cfg *config.Config, configPath string, args []string) error {
+	dryRun, args := popFlag(args, "--dry-run")
+	keepOnFailure, args := popFlag(args, "--keep-on-failure")
+	if dryRun {
+		if out.JSON() {
+			return fmt.Errorf("--dry-run does not support --json")
+		}
+		return cmdPlan(cfg, configPath, args)
+	}
+
+	profile, err := parseProfileFlag(args)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyProfile(profile); err != nil {
+		return err
+	}
+
+	if cfg.MigratedFrom != "" {
+		out.Warnf("config version %s is outdated; run 'devlog migrate' to upgrade to version %s", cfg.MigratedFrom, config.CurrentVersion)
+	}
+
+	out.Printf("Starting devlog session '%s'...\n", cfg.Tmux.Session)
+	if cfg.Profile != "" {
+		out.Printf("Profile: %s\n", cfg.Profile)
+	}
 
 	// Create tmux runner
-	runner := tmux.NewRunner(cfg.Tmux.Session)
+	runner := newRunner(cfg)
 
 	// Check if session already exists
 	if runner.SessionExists() {
-		return fmt.Errorf("tmux session '%s' already exists. Run 'devlog down' first or use 'tmux attach -t %s' to attach", cfg.Tmux.Session, cfg.Tmux.Session)
+		return fmt.Errorf("tmux session '%s' already exists. Run 'devlog down' first or use 'devlog attach' to attach", cfg.Tmux.Session)
 	}
 
+	baseLogsDir := cfg.ResolveLogsDir()
+
 	// Clean up old log runs if retention policy is configured
 	if cfg.RunMode == "timestamped" {
 		policy := logrotate.Policy{MaxRuns: cfg.MaxRuns, RetentionDays: cfg.RetentionDays}
-		if result, err := logrotate.Cleanup(cfg.LogsDir, policy, false); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to cleanup old runs: %v\n", err)
+		if result, err := logrotate.Cleanup(baseLogsDir, policy, false); err != nil {
+			out.Warnf("failed to cleanup old runs: %v", err)
 		} else if result != nil {
 			for _, dir := range result.Removed {
-				fmt.Printf("Removed old log directory: %s\n", dir)
+				out.Printf("Removed old log directory: %s\n", dir)
 			}
 			for dir, remErr := range result.Failed {
-				fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", dir, remErr)
+				out.Warnf("failed to remove %s: %v", dir, remErr)
 			}
 		}
 	}
 
-	// Create the tmux session (Runner resolves logs dir internally)
-	sessionCfg := tmux.SessionConfig{
+	runDir := tmux.RunDir(baseLogsDir, cfg.RunMode, time.Now())
+	_, statErr := os.Stat(runDir)
+	newRunDir := os.IsNotExist(statErr)
+
+	// removeRunDir drops a run directory this up created if nothing but
+	// devlog's own bookkeeping was written to it
+	removeRunDir := func() {
+		if !newRunDir {
+			return
+		}
+		if err := tmux.RemoveRunDir(runDir, hooks.LogFile, tmux.PaneEventsFile); err != nil {
+			out.Warnf("rollback incomplete: %v", err)
+		}
+	}
+
+	hookRunner := newHookRunner(cfg, runDir)
+	if err := hookRunner.Run("pre_up", cfg.Hooks.PreUp); err != nil {
+		removeRunDir()
+		return fmt.Errorf("aborting up: %w", err)
+	}
+
+	// Create the tmux session in the run directory chosen above
+	plan, err := runner.Plan(tmux.SessionConfig{
 		Session: cfg.Tmux.Session,
-		LogsDir: cfg.LogsDir,
+		LogsDir: baseLogsDir,
 		RunMode: cfg.RunMode,
+		RunDir:  runDir,
+		Profile: cfg.Profile,
+		Devlog:  devlogExecutable(),
+		Redact:  cfg.Redact,
 		Windows: cfg.Tmux.Windows,
+	})
+	if err != nil {
+		removeRunDir()
+		return err
+	}
+	result := UpOutput{
+		OK:      true,
+		Session: cfg.Tmux.Session,
+		Profile: cfg.Profile,
+		LogsDir: runDir,
+		Windows: len(cfg.Tmux.Windows),
+	}
+
+	// up is all or nothing: on failure, undo everything done so far
+	var browserLogPath string
+	var bs *browsersession.Session
+	rollback := func(cause error) error {
+		if err := runner.Rollback(plan); err != nil {
+			out.Warnf("rollback incomplete: %v", err)
+		}
+		if bs != nil {
+			bs.Stop(cfg.Tmux.Session)
+		}
+		if browserLogPath != "" {
+			tmux.RemoveIfEmpty(browserLogPath)
+		}
+		removeRunDir()
+		return fmt.Errorf("%w (session '%s' was rolled back)", cause, cfg.Tmux.Session)
 	}
-	if err := runner.CreateSession(sessionCfg); err != nil {
-		return fmt.Errorf("failed to create tmux session: %w", err)
+
+	if err := runner.Execute(plan, out.info()); err != nil {
+		var notReady *tmux.NotReadyError
+		if !errors.As(err, &notReady) {
+			return rollback(fmt.Errorf("failed to create tmux session: %w", err))
+		}
+		err := fmt.Errorf("%d pane(s) failed readiness checks", len(notReady.NotReady)+len(notReady.NotStarted))
+		if !keepOnFailure {
+			err = rollback(err)
+		}
+		if out.JSON() {
+			result.OK = false
+			result.NotReady = paneFailures(notReady.NotReady)
+			result.NotStarted = paneFailures(notReady.NotStarted)
+			if jsonErr := out.PrintJSON(result); jsonErr != nil {
+				return jsonErr
+			}
+			return silentError{err}
+		}
+		printNotReady(cfg.Tmux.Session, notReady, keepOnFailure)
+		return err
 	}
 	logsDir := runner.GetLogsDir()
-	fmt.Printf("Logs will be written to: %s\n", logsDir)
+	result.LogsDir = logsDir
+	out.Printf("Logs will be written to: %s\n", logsDir)
 
-	fmt.Printf("Created tmux session '%s' with %d window(s)\n", cfg.Tmux.Session, len(cfg.Tmux.Windows))
+	out.Printf("Created tmux session '%s' with %d window(s)\n", cfg.Tmux.Session, len(cfg.Tmux.Windows))
 
 	// Set up browser logging wrapper if configured
 	if len(cfg.Browser.URLs) > 0 && cfg.Browser.File != "" {
-		browserLogPath := filepath.Join(logsDir, cfg.Browser.File)
-		if err := ensureFileExists(browserLogPath); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to prepare browser log file: %v\n", err)
+		path := config.RunFilePath(logsDir, cfg.Browser.File)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			browserLogPath = path
+		}
+		if err := ensureFileExists(path); err != nil {
+			return rollback(fmt.Errorf("failed to prepare browser log file: %w", err))
 		}
-		bs := browsersession.New(manifestAdapter{}, tmuxSessionChecker{})
-		if err := bs.Start(cfg.Tmux.Session, browserLogPath, cfg.Browser.Levels); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to set up browser logging wrapper: %v\n", err)
+		bs = browsersession.New(manifestAdapter{}, tmuxSessionChecker{})
+		if err := bs.Start(cfg.Tmux.Session, path, browserHostArgs(cfg)); err != nil {
+			if !errors.Is(err, browsersession.ErrHostNotFound) {
+				return rollback(fmt.Errorf("failed to set up browser logging: %w", err))
+			}
+			out.Warnf("browser logging disabled: %v", err)
 		} else {
-			fmt.Println("Browser logging: ready (wrapper updated)")
+			result.BrowserLogFile = path
+			out.Println("Browser logging: ready (wrapper updated)")
 		}
 	}
 
-	fmt.Printf("Attach with: devlog attach\n")
+	if err := hookRunner.Run("post_up", cfg.Hooks.PostUp); err != nil {
+		out.Warnf("%v", err)
+	}
+
+	if out.JSON() {
+		return out.PrintJSON(result)
+	}
+	out.Printf("Attach with: devlog attach\n")
 
 	return nil
 }
+
+func paneFailures(failures []tmux.PaneFailure) []PaneFailure {
+	var list []PaneFailure
+	for _, f := range failures {
+		list = append(list, PaneFailure{Pane: f.Pane, Reason: f.Reason})
+	}
+	return list
+}
+
+// printNotReady explains which panes failed readiness checks and which were
+// held back, and how to inspect the session when it was kept running.
+func printNotReady(session string, e *tmux.NotReadyError, kept bool) {
+	fmt.Fprintln(out.stderr, "Readiness checks failed:")
+	for _, f := range e.NotReady {
+		fmt.Fprintf(out.stderr, "  %s %s: %s\n", out.red("✗"), f.Pane, f.Reason)
+	}
+	for _, f := range e.NotStarted {
+		fmt.Fprintf(out.stderr, "  - %s: not started, %s\n", f.Pane, f.Reason)
+	}
+	if kept {
+		fmt.Fprintf(out.stderr, "Session '%s' was left running for inspection; use 'devlog attach' to look, 'devlog down' to stop it.\n", session)
+	}
+}
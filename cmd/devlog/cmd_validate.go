@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+func cmdValidate(cfg *config.Config, configPath string, args []string) error {
+	for _, arg := range args {
+		switch {
+		case arg == "--schema":
+			schema, err := config.JSONSchema()
+			if err != nil {
+				return fmt.Errorf("failed to generate JSON Schema: %w", err)
+			}
+			_, err = os.Stdout.Write(schema)
+			return err
+		case arg == "--help" || arg == "-h":
+			return cmdHelp(nil, "", []string{"validate"})
+		case strings.HasPrefix(arg, "-"):
+			return fmt.Errorf("unknown argument: %s (use --help for usage)", arg)
+		default:
+			configPath = arg
+		}
+	}
+
+	if configPath == "" {
+		return errConfigNotFound
+	}
+
+	problems := config.CheckFile(configPath)
+	if len(problems) == 0 {
+		out.Printf("%s: OK\n", configPath)
+		if _, changes, err := config.MigrateFile(configPath, false); err == nil && len(changes) > 0 {
+			out.Warnf("config version is outdated; run 'devlog migrate' to upgrade to version %s", config.CurrentVersion)
+		}
+		return nil
+	}
+
+	for _, p := range problems {
+		fmt.Fprintln(out.stderr, p.String())
+	}
+	return fmt.Errorf("found %d problem(s) in %s", len(problems), configPath)
+}
@@ -13,7 +13,7 @@ func TestCmdHealthcheck_Basic(t *testing.T) {
 	t.Chdir(tmpDir)
 
 	// Run healthcheck command
-	err := cmdHealthcheck(nil, nil)
+	err := cmdHealthcheck(nil, "", nil)
 
 	// Healthcheck may fail if tmux or devlog-host is not installed,
 	// but it should not panic or crash
@@ -28,7 +28,7 @@ func TestCmdHealthcheck_WithArgs(t *testing.T) {
 	t.Chdir(tmpDir)
 
 	// Run healthcheck command with args (should be ignored)
-	err := cmdHealthcheck(nil, []string{"--some-arg"})
+	err := cmdHealthcheck(nil, "", []string{"--some-arg"})
 
 	// Healthcheck should work with or without args
 	if err != nil && !strings.Contains(err.Error(), "healthcheck failed") {
@@ -61,7 +61,7 @@ func TestHealthcheckDoesNotRequireConfig(t *testing.T) {
 	}
 
 	// Run healthcheck - should not fail due to missing config
-	err := cmdHealthcheck(nil, nil)
+	err := cmdHealthcheck(nil, "", nil)
 
 	// It may fail healthcheck, but should not fail due to missing config
 	if err != nil && !strings.Contains(err.Error(), "healthcheck failed") {
@@ -106,7 +106,7 @@ func TestCmdHealthcheck_DetectsBraveManifest(t *testing.T) {
 	// Act - capture stdout to verify Brave is detected
 	// Note: This is a behavior test - we're checking that Brave manifests
 	// are properly detected during healthcheck
-	err := cmdHealthcheck(nil, nil)
+	err := cmdHealthcheck(nil, "", nil)
 
 	// Assert - The function should complete without panicking
 	// The actual Brave detection happens via GetBraveNativeMessagingDir()
@@ -132,7 +132,7 @@ func TestCmdHealthcheck_LabelFormatting(t *testing.T) {
 	t.Chdir(tmpDir)
 
 	// Act - Run healthcheck (it may fail, but we're testing formatting)
-	err := cmdHealthcheck(nil, nil)
+	err := cmdHealthcheck(nil, "", nil)
 
 	// Assert - Should complete without crash
 	// The actual formatting is verified by visual inspection
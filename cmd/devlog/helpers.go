@@ -1,8 +1,19 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/hooks"
+	"github.com/jellydn/devlog/internal/logger"
+	"github.com/jellydn/devlog/internal/logrotate"
+	"github.com/jellydn/devlog/internal/redact"
+	"github.com/jellydn/devlog/internal/tmux"
 )
 
 // maxFindConfigDepth limits how far findConfigFile walks up the directory tree.
@@ -10,6 +21,15 @@ import (
 // walk all the way to the filesystem root when invoked from an unrelated path.
 const maxFindConfigDepth = 20
 
+// configFileNames are the config file names findConfigFile looks for in each
+// directory, in order of preference.
+var configFileNames = []string{"devlog.yml", "devlog.yaml", ".devlog.yml", ".devlog.yaml"}
+
+// configEnvVar names the environment variable that selects the config file.
+const configEnvVar = "DEVLOG_CONFIG"
+
+var errConfigNotFound = errors.New("devlog.yml not found in current directory or parent directories (also tried devlog.yaml, .devlog.yml, .devlog.yaml; use --config or DEVLOG_CONFIG to pick a file)")
+
 func findConfigFile() string {
 	dir, err := os.Getwd()
 	if err != nil {
@@ -17,9 +37,11 @@ func findConfigFile() string {
 	}
 
 	for depth := 0; depth < maxFindConfigDepth; depth++ {
-		configPath := filepath.Join(dir, "devlog.yml")
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath
+		for _, name := range configFileNames {
+			configPath := filepath.Join(dir, name)
+			if _, err := os.Stat(configPath); err == nil {
+				return configPath
+			}
 		}
 
 		// Go up one directory
@@ -34,6 +56,161 @@ func findConfigFile() string {
 	return ""
 }
 
+// resolveConfigPath picks the config file: the --config flag, then
+// DEVLOG_CONFIG, then findConfigFile. explicit reports whether the path was
+// given by the user rather than discovered.
+func resolveConfigPath(flagValue string) (path string, explicit bool) {
+	if flagValue != "" {
+		return flagValue, true
+	}
+	if env := os.Getenv(configEnvVar); env != "" {
+		return env, true
+	}
+	return findConfigFile(), false
+}
+
+// globalOptions are the flags every command accepts before the command name,
+// and after it for the commands that declare them in commandFlagSpecs.
+type globalOptions struct {
+	ConfigPath string // --config, -c
+	JSON       bool   // --json
+	Quiet      bool   // --quiet, -q
+	NoColor    bool   // --no-color
+	Verbose    bool   // --verbose, -v: echo every tmux command
+}
+
+// globalFlagNames are the global flags as written on the command line.
+var globalFlagNames = []string{"--config", "-c", "--json", "--quiet", "-q", "--no-color", "--verbose", "-v"}
+
+// commandFlags declares how a command's arguments mix with the global flags.
+type commandFlags struct {
+	globals []string // global flags also accepted after the command name
+	values  []string // the command's own flags that take a value, which is never read as a flag
+}
+
+// commandFlagSpecs lists every command whose arguments are scanned for
+// global flags and --help. Arguments of commands not listed are passed
+// through untouched.
+var commandFlagSpecs = map[string]commandFlags{
+	"init":        {globals: globalFlagNames},
+	"up":          {globals: globalFlagNames, values: []string{"--profile"}},
+	"plan":        {globals: globalFlagNames, values: []string{"--profile"}},
+	"down":        {globals: globalFlagNames, values: []string{"--profile"}},
+	"attach":      {globals: globalFlagNames},
+	"status":      {globals: globalFlagNames, values: []string{"--profile"}},
+	"ls":          {globals: globalFlagNames},
+	"config":      {globals: globalFlagNames},
+	"validate":    {globals: globalFlagNames},
+	"migrate":     {globals: globalFlagNames},
+	"open":        {globals: globalFlagNames},
+	"register":    {globals: globalFlagNames, values: []string{"--extension-id"}},
+	"healthcheck": {globals: globalFlagNames},
+	// Run by tmux; pattern and path values may look like global flags
+	"pipe": {values: []string{"--timestamps", "--start", "--raw",
+		redact.PresetFlag, redact.PatternFlag, logrotate.MaxFileSizeFlag, logrotate.MaxFilesFlag}},
+	// Run by tmux; the pane's command comes after "--"
+	"run": {values: []string{tmux.EventsFlag, tmux.NameFlag, tmux.LabelFlag, tmux.EnvFlag,
+		tmux.RestartFlag, tmux.MaxRetriesFlag, tmux.BackoffFlag}},
+}
+
+// parseGlobalFlags parses the global flags before the command name, then the
+// ones the command accepts after it (see commandFlagSpecs), and returns them
+// with the command name and its remaining arguments. Arguments after "--" are
+// left alone.
+func parseGlobalFlags(args []string) (globalOptions, []string, error) {
+	var opts globalOptions
+	i := 0
+	for i < len(args) {
+		n, err := opts.parseFlag(args[i:], globalFlagNames)
+		if err != nil {
+			return opts, nil, err
+		}
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	if i == len(args) {
+		return opts, nil, nil
+	}
+
+	rest := []string{args[i]}
+	spec := commandFlagSpecs[args[i]]
+	for i++; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return opts, append(rest, args[i:]...), nil
+		}
+		if slices.Contains(spec.values, arg) && i+1 < len(args) {
+			rest = append(rest, arg, args[i+1])
+			i++
+			continue
+		}
+		n, err := opts.parseFlag(args[i:], spec.globals)
+		if err != nil {
+			return opts, nil, err
+		}
+		if n == 0 {
+			rest = append(rest, arg)
+			continue
+		}
+		i += n - 1
+	}
+	return opts, rest, nil
+}
+
+// parseFlag parses args[0] when it is one of the global flags in names and
+// returns how many arguments it used: 0 when args[0] is not such a flag.
+func (o *globalOptions) parseFlag(args []string, names []string) (int, error) {
+	name, value, hasValue := strings.Cut(args[0], "=")
+	if !slices.Contains(names, name) || (hasValue && name != "--config") {
+		return 0, nil
+	}
+	switch name {
+	case "--config", "-c":
+		if hasValue {
+			o.ConfigPath = value
+			return 1, nil
+		}
+		if len(args) < 2 {
+			return 0, fmt.Errorf("%s requires a value", name)
+		}
+		o.ConfigPath = args[1]
+		return 2, nil
+	case "--json":
+		o.JSON = true
+	case "--quiet", "-q":
+		o.Quiet = true
+	case "--no-color":
+		o.NoColor = true
+	case "--verbose", "-v":
+		o.Verbose = true
+	}
+	return 1, nil
+}
+
+// hasHelpFlag reports whether a command's args ask for help. Values of the
+// command's value flags are skipped, and commands missing from
+// commandFlagSpecs are never scanned.
+func hasHelpFlag(command string, args []string) bool {
+	spec, ok := commandFlagSpecs[command]
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return false
+		case slices.Contains(spec.values, arg):
+			i++
+		case arg == "--help" || arg == "-h":
+			return true
+		}
+	}
+	return false
+}
+
 func ensureFileExists(path string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
@@ -44,3 +221,83 @@ func ensureFileExists(path string) error {
 	}
 	return f.Close()
 }
+
+// parseProfileFlag extracts --profile <name> (or --profile=<name>) from args.
+// Any other argument is rejected.
+func parseProfileFlag(args []string) (string, error) {
+	profile := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--profile":
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("--profile requires a value")
+			}
+			profile = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--profile="):
+			profile = strings.TrimPrefix(arg, "--profile=")
+		default:
+			return "", fmt.Errorf("unknown argument: %s", arg)
+		}
+	}
+	return profile, nil
+}
+
+// popFlag removes every occurrence of the boolean flag from args and reports
+// whether it was present.
+func popFlag(args []string, flag string) (bool, []string) {
+	found := false
+	var rest []string
+	for _, arg := range args {
+		if arg == flag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return found, rest
+}
+
+// newRunner returns a tmux runner for cfg's session on the tmux server
+// selected by tmux.socket and tmux.config.
+func newRunner(cfg *config.Config) *tmux.Runner {
+	return tmux.NewServerRunner(cfg.Tmux.Session, tmuxServer(cfg))
+}
+
+func tmuxServer(cfg *config.Config) tmux.Server {
+	return tmux.Server{Socket: cfg.TmuxSocket(), Config: cfg.ResolveTmuxConfig()}
+}
+
+// devlogExecutable returns the path of the running devlog binary, which
+// panes are started with (`devlog run`) and pipe-pane runs (`devlog pipe`),
+// falling back to devlog from PATH.
+func devlogExecutable() string {
+	if path, err := os.Executable(); err == nil {
+		return path
+	}
+	return "devlog"
+}
+
+// browserHostArgs returns the devlog-host arguments after the log path: the
+// log format, redact rules, log rotation and the captured levels.
+func browserHostArgs(cfg *config.Config) []string {
+	args := logger.Args(cfg.Browser.FormatOptions())
+	args = append(args, redact.Args(cfg.Redact.Presets, cfg.Redact.Patterns)...)
+	args = append(args, logrotate.Args(cfg.Browser.LogRotation())...)
+	return append(args, cfg.Browser.Levels...)
+}
+
+// newHookRunner prepares lifecycle hooks for cfg, writing to runDir/hooks.log.
+func newHookRunner(cfg *config.Config, runDir string) hooks.Runner {
+	return hooks.Runner{
+		Dir:     cfg.Dir,
+		LogsDir: runDir,
+		Env: map[string]string{
+			"DEVLOG_PROJECT": cfg.Project,
+			"DEVLOG_SESSION": cfg.Tmux.Session,
+			"DEVLOG_PROFILE": cfg.Profile,
+		},
+		Stdout: out.info(),
+	}
+}
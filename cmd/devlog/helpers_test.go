@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseProfileFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{args: nil, want: ""},
+		{args: []string{"--profile", "frontend"}, want: "frontend"},
+		{args: []string{"--profile=workers"}, want: "workers"},
+		{args: []string{"--profile"}, wantErr: "requires a value"},
+		{args: []string{"--bogus"}, wantErr: "unknown argument"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseProfileFlag(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseProfileFlag(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseProfileFlag(%v) failed: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseProfileFlag(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		want     globalOptions
+		wantRest []string
+		wantErr  string
+	}{
+		{args: []string{"up"}, wantRest: []string{"up"}},
+		{args: []string{"--config", "devlog.e2e.yml", "up", "--profile", "x"}, want: globalOptions{ConfigPath: "devlog.e2e.yml"}, wantRest: []string{"up", "--profile", "x"}},
+		{args: []string{"--config=a.yml", "status"}, want: globalOptions{ConfigPath: "a.yml"}, wantRest: []string{"status"}},
+		{args: []string{"-c", "b.yaml", "down"}, want: globalOptions{ConfigPath: "b.yaml"}, wantRest: []string{"down"}},
+		{args: []string{"status", "--json", "--no-color"}, want: globalOptions{JSON: true, NoColor: true}, wantRest: []string{"status"}},
+		{args: []string{"-q", "up", "-v", "--profile=web"}, want: globalOptions{Quiet: true, Verbose: true}, wantRest: []string{"up", "--profile=web"}},
+		{args: []string{"up", "--", "--json"}, wantRest: []string{"up", "--", "--json"}},
+		{args: []string{"status", "--profile", "-v"}, wantRest: []string{"status", "--profile", "-v"}},
+		{args: []string{"-v", "pipe", "--redact-pattern", "-v", "--sanitize", "-q", "out.log"}, want: globalOptions{Verbose: true}, wantRest: []string{"pipe", "--redact-pattern", "-v", "--sanitize", "-q", "out.log"}},
+		{args: []string{"--config"}, wantErr: "--config requires a value"},
+	}
+
+	for _, tt := range tests {
+		opts, rest, err := parseGlobalFlags(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("parseGlobalFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseGlobalFlags(%v) failed: %v", tt.args, err)
+			continue
+		}
+		if opts != tt.want || strings.Join(rest, " ") != strings.Join(tt.wantRest, " ") {
+			t.Errorf("parseGlobalFlags(%v) = %+v, %v; want %+v, %v", tt.args, opts, rest, tt.want, tt.wantRest)
+		}
+	}
+}
+
+func TestResolveConfigPath(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "packages", "web")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	dotfile := filepath.Join(root, ".devlog.yaml")
+	if err := os.WriteFile(dotfile, []byte("version: \"2\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+	t.Setenv(configEnvVar, "")
+
+	if path, explicit := resolveConfigPath(""); path != dotfile || explicit {
+		t.Errorf("resolveConfigPath() = %q, %v; want discovered %q", path, explicit, dotfile)
+	}
+
+	t.Setenv(configEnvVar, "from-env.yml")
+	if path, explicit := resolveConfigPath(""); path != "from-env.yml" || !explicit {
+		t.Errorf("resolveConfigPath() = %q, %v; want DEVLOG_CONFIG value", path, explicit)
+	}
+	if path, explicit := resolveConfigPath("from-flag.yml"); path != "from-flag.yml" || !explicit {
+		t.Errorf("resolveConfigPath(flag) = %q, %v; want flag value", path, explicit)
+	}
+}
+
+func TestFindConfigFile_PrefersDevlogYml(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{".devlog.yml", "devlog.yaml", "devlog.yml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	if got := findConfigFile(); filepath.Base(got) != "devlog.yml" {
+		t.Errorf("findConfigFile() = %q, want devlog.yml", got)
+	}
+}
@@ -11,7 +11,7 @@ func TestCmdInit_CreatesFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Chdir(tmpDir)
 
-	err := cmdInit(nil, nil)
+	err := cmdInit(nil, "", nil)
 	if err != nil {
 		t.Fatalf("cmdInit() failed: %v", err)
 	}
@@ -65,7 +65,7 @@ func TestCmdInit_FileAlreadyExists(t *testing.T) {
 	}
 
 	// Try to init again
-	err := cmdInit(nil, nil)
+	err := cmdInit(nil, "", nil)
 	if err == nil {
 		t.Fatal("cmdInit() should have failed with existing file")
 	}
@@ -115,7 +115,7 @@ func TestCmdInit_MonorepoDetection(t *testing.T) {
 				}
 			}
 
-			err := cmdInit(nil, nil)
+			err := cmdInit(nil, "", nil)
 			if err != nil {
 				t.Fatalf("cmdInit() failed: %v", err)
 			}
@@ -211,3 +211,18 @@ func TestGenerateTemplate_Monorepo(t *testing.T) {
 		t.Error("Monorepo template should contain pnpm --filter command")
 	}
 }
+
+func TestCmdInit_ExplicitPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	if err := cmdInit(nil, "devlog.e2e.yml", nil); err != nil {
+		t.Fatalf("cmdInit() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "devlog.e2e.yml")); err != nil {
+		t.Errorf("devlog.e2e.yml was not created: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "devlog.yml")); err == nil {
+		t.Error("devlog.yml was created, want only the explicit path")
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import "time"
+
+// The structs below are the --json output of status, ls, healthcheck and up.
+// Field names are part of devlog's interface: add fields freely, but do not
+// rename or remove them.
+
+// StatusOutput is printed by `devlog status --json`.
+type StatusOutput struct {
+	Project string `json:"project"`
+	Session string `json:"session"`
+	RunMode string `json:"run_mode"`
+	Profile string `json:"profile,omitempty"`
+	Running bool   `json:"running"`
+	// The fields below are only set while the session is running.
+	LogsDir  string          `json:"logs_dir,omitempty"`
+	Windows  []WindowStatus  `json:"windows,omitempty"`
+	LogFiles []LogFileStatus `json:"log_files,omitempty"`
+	Browser  *BrowserStatus  `json:"browser,omitempty"`
+}
+
+// WindowStatus is a tmux window of the running session.
+type WindowStatus struct {
+	Index int          `json:"index"`
+	Name  string       `json:"name"`
+	Panes []PaneStatus `json:"panes"`
+}
+
+// PaneStatus is a tmux pane of the running session.
+type PaneStatus struct {
+	ID      string `json:"id"`
+	Index   int    `json:"index"`
+	Name    string `json:"name,omitempty"`
+	Command string `json:"command"`
+
+	// Last start or exit of the pane's command, from the run's pane events
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	ExitedAt  *time.Time `json:"exited_at,omitempty"`
+	ExitCode  *int       `json:"exit_code,omitempty"`
+	Signal    int        `json:"signal,omitempty"` // signal that killed the command, if any
+}
+
+// LogFileStatus is a log file of the current run.
+type LogFileStatus struct {
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	Size   int64  `json:"size"`
+}
+
+// BrowserStatus describes browser log capture.
+type BrowserStatus struct {
+	Enabled bool           `json:"enabled"`
+	URLs    []string       `json:"urls"`
+	LogFile *LogFileStatus `json:"log_file,omitempty"`
+	Levels  []string       `json:"levels,omitempty"`
+}
+
+// LsOutput is printed by `devlog ls --json`.
+type LsOutput struct {
+	LogsDir string `json:"logs_dir"`
+	RunMode string `json:"run_mode"`
+	Exists  bool   `json:"exists"`
+	// Runs lists the run directories in timestamped mode; in overwrite mode
+	// it holds the logs directory itself.
+	Runs []RunEntry `json:"runs"`
+}
+
+// RunEntry is one log run directory.
+type RunEntry struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	Files    int       `json:"files"`
+	Modified time.Time `json:"modified"`
+}
+
+// HealthcheckOutput is printed by `devlog healthcheck --json`.
+type HealthcheckOutput struct {
+	OK     bool          `json:"ok"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult is a single healthcheck.
+type CheckResult struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// UpOutput is printed by `devlog up --json`, including when readiness checks
+// fail (ok is false and the failing panes are listed).
+type UpOutput struct {
+	OK             bool          `json:"ok"`
+	Session        string        `json:"session"`
+	Profile        string        `json:"profile,omitempty"`
+	LogsDir        string        `json:"logs_dir"`
+	Windows        int           `json:"windows"`
+	BrowserLogFile string        `json:"browser_log_file,omitempty"`
+	NotReady       []PaneFailure `json:"not_ready,omitempty"`
+	NotStarted     []PaneFailure `json:"not_started,omitempty"`
+}
+
+// PaneFailure is a pane that failed, or was held back by, readiness checks.
+type PaneFailure struct {
+	Pane   string `json:"pane"`
+	Reason string `json:"reason"`
+}
@@ -1,112 +1,157 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/tmux"
 )
 
-const usage = `Usage: devlog <command> [args...]
+const usage = `Usage: devlog [global options] <command> [args...]
 
 Commands:
   init        Create a devlog.yml template in current directory
-  up          Start tmux session and browser logging
-  down        Stop tmux session and flush logs
+  up          Start tmux session and browser logging (--profile <name>, --dry-run)
+  plan        Show what 'devlog up' would do without doing it
+  down        Stop tmux session and flush logs (--profile <name>)
   attach      Attach to the running tmux session
-  status      Show session state and log paths
+  status      Show session state and log paths (--profile <name>)
   ls          List log runs
+  config      Show merged config values and the file each came from
+  validate    Check devlog.yml for errors (--schema prints its JSON Schema)
+  migrate     Upgrade devlog.yml to the current config version
   open        Open logs directory in file manager
   register    Register native messaging host for browser logging
   healthcheck Check system requirements (tmux, browser extension)
-  help        Show this help message
+  pipe        Copy pane output to a log file (used by tmux pipe-pane)
+  run         Run a pane's command (used by tmux to start panes)
+  help        Show this help message, or a command's with 'devlog help <command>'
 
 Examples:
   devlog init
   devlog healthcheck
+  devlog validate
   devlog up
+  devlog up --profile frontend
+  devlog up --dry-run
+  devlog --config devlog.e2e.yml up
   devlog attach
   devlog status
+  devlog status --json
   devlog ls
   devlog down
   devlog register --chrome --extension-id abcdefghijklmnop
-`
 
-type Command func(cfg *config.Config, args []string) error
+` + globalUsage
+
+// Command runs a subcommand. cfg is nil for commands that run without a
+// loaded config; configPath is the file cfg was loaded from, or for those
+// commands the --config/DEVLOG_CONFIG/discovered path ("" when none).
+type Command func(cfg *config.Config, configPath string, args []string) error
 
 var commands = map[string]Command{
 	"init":        cmdInit,
 	"up":          cmdUp,
+	"plan":        cmdPlan,
 	"down":        cmdDown,
 	"attach":      cmdAttach,
 	"status":      cmdStatus,
 	"ls":          cmdLs,
+	"config":      cmdConfig,
+	"validate":    cmdValidate,
+	"migrate":     cmdMigrate,
 	"open":        cmdOpen,
 	"help":        cmdHelp,
 	"register":    cmdRegister,
 	"healthcheck": cmdHealthcheck,
+	"pipe":        cmdPipe,
+	"run":         cmdRun,
 }
 
 func main() {
-	if len(os.Args) < 2 {
+	opts, args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	out = newOutput(opts)
+	if opts.Verbose {
+		tmux.SetTrace(os.Stderr)
+	}
+	if len(args) < 1 {
 		fmt.Fprint(os.Stderr, usage)
 		os.Exit(1)
 	}
 
-	command := os.Args[1]
+	command := args[0]
 
 	// Help command doesn't need config
 	if command == "help" || command == "--help" || command == "-h" {
-		cmdHelp(nil, nil)
-		os.Exit(0)
+		runCommand(cmdHelp, nil, "", args[1:])
+		return
+	}
+
+	cmd, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n%s", command, usage)
+		os.Exit(1)
+	}
+	if hasHelpFlag(command, args[1:]) {
+		runCommand(cmdHelp, nil, "", args[:1])
+		return
+	}
+	if opts.JSON && !jsonCommands[command] {
+		exitError(fmt.Errorf("devlog %s does not support --json", command))
+	}
+
+	configPath, explicit := resolveConfigPath(opts.ConfigPath)
+	if command == "init" && !explicit {
+		// init creates a new file here rather than reusing one found in a parent
+		configPath = ""
 	}
 
 	// Commands that don't need config
-	if command == "init" || command == "register" || command == "healthcheck" {
-		runCommandWithoutConfig(command)
+	if command == "init" || command == "register" || command == "healthcheck" || command == "validate" || command == "migrate" || command == "pipe" || command == "run" {
+		runCommand(cmd, nil, configPath, args[1:])
 		return
 	}
 
-	// Find config file
-	configPath := findConfigFile()
 	if configPath == "" {
-		fmt.Fprintln(os.Stderr, "Error: devlog.yml not found in current directory or parent directories")
-		os.Exit(1)
+		exitError(errConfigNotFound)
+	}
+	if explicit {
+		if _, err := os.Stat(configPath); err != nil {
+			exitError(fmt.Errorf("config file %s not found", configPath))
+		}
 	}
 
 	// Load config
 	cfg, err := config.Load(configPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Execute command
-	cmd, ok := commands[command]
-	if !ok {
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n%s", command, usage)
-		os.Exit(1)
+		exitError(fmt.Errorf("loading config: %w", err))
 	}
 
-	if err := cmd(cfg, os.Args[2:]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	runCommand(cmd, cfg, configPath, args[1:])
 }
 
-func runCommandWithoutConfig(command string) {
-	cmd, ok := commands[command]
-	if !ok {
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n%s", command, usage)
-		os.Exit(1)
-	}
-	if err := cmd(nil, os.Args[2:]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+func runCommand(cmd Command, cfg *config.Config, configPath string, args []string) {
+	if err := cmd(cfg, configPath, args); err != nil {
+		exitError(err)
 	}
 }
 
-func cmdHelp(cfg *config.Config, args []string) error {
-	fmt.Print(usage)
-	return nil
+// exitError reports err (as {"error": ...} with --json) and exits with
+// status 1. A silentError was already reported by the command.
+func exitError(err error) {
+	var silent silentError
+	switch {
+	case errors.As(err, &silent):
+	case out.JSON():
+		out.PrintJSON(map[string]string{"error": err.Error()})
+	default:
+		fmt.Fprintf(os.Stderr, "%s %v\n", out.red("Error:"), err)
+	}
+	os.Exit(1)
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// output prints command results according to the global flags. Commands
+// print through out rather than fmt so --quiet, --json and --no-color apply
+// everywhere.
+type output struct {
+	stdout io.Writer
+	stderr io.Writer
+	json   bool // results are printed as JSON; informational text is dropped
+	quiet  bool // informational text is dropped; errors and warnings remain
+	color  bool // ANSI colors in marks and warnings
+}
+
+// out is the output used by all commands; main configures it from the
+// global flags before running a command.
+var out = newOutput(globalOptions{})
+
+func newOutput(opts globalOptions) *output {
+	return &output{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		json:   opts.JSON,
+		quiet:  opts.Quiet,
+		color:  !opts.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout),
+	}
+}
+
+// info returns the writer for informational text: stdout normally,
+// io.Discard with --quiet or --json.
+func (o *output) info() io.Writer {
+	if o.quiet || o.json {
+		return io.Discard
+	}
+	return o.stdout
+}
+
+func (o *output) Printf(format string, args ...any) {
+	fmt.Fprintf(o.info(), format, args...)
+}
+
+func (o *output) Println(args ...any) {
+	fmt.Fprintln(o.info(), args...)
+}
+
+// Warnf prints a warning to stderr. Warnings are kept with --quiet.
+func (o *output) Warnf(format string, args ...any) {
+	fmt.Fprintf(o.stderr, "%s %s\n", o.yellow("Warning:"), fmt.Sprintf(format, args...))
+}
+
+// JSON reports whether results should be printed with PrintJSON.
+func (o *output) JSON() bool {
+	return o.json
+}
+
+// PrintJSON writes v to stdout as indented JSON.
+func (o *output) PrintJSON(v any) error {
+	enc := json.NewEncoder(o.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (o *output) green(s string) string  { return o.paint("32", s) }
+func (o *output) red(s string) string    { return o.paint("31", s) }
+func (o *output) yellow(s string) string { return o.paint("33", s) }
+
+func (o *output) paint(code, s string) string {
+	if !o.color {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// isTerminal reports whether f is a character device (a terminal).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// silentError makes main exit non-zero without printing, for commands that
+// already reported the failure (e.g. as JSON).
+type silentError struct {
+	error
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+// captureOutput points out at buffers for the duration of the test.
+func captureOutput(t *testing.T, opts globalOptions) (stdout, stderr *bytes.Buffer) {
+	t.Helper()
+	stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+	prev := out
+	out = &output{stdout: stdout, stderr: stderr, json: opts.JSON, quiet: opts.Quiet}
+	t.Cleanup(func() { out = prev })
+	return stdout, stderr
+}
+
+func TestOutput_Quiet(t *testing.T) {
+	stdout, stderr := captureOutput(t, globalOptions{Quiet: true})
+
+	out.Printf("Starting %s\n", "dev")
+	out.Warnf("disk %s", "full")
+
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q, want nothing with --quiet", stdout.String())
+	}
+	if got := stderr.String(); got != "Warning: disk full\n" {
+		t.Errorf("stderr = %q, want the warning", got)
+	}
+}
+
+func TestOutput_Color(t *testing.T) {
+	o := &output{color: true}
+	if got := o.red("x"); got != "\x1b[31mx\x1b[0m" {
+		t.Errorf("red() = %q", got)
+	}
+	o.color = false
+	if got := o.red("x"); got != "x" {
+		t.Errorf("red() without color = %q", got)
+	}
+}
+
+func TestCmdLs_JSON(t *testing.T) {
+	stdout, _ := captureOutput(t, globalOptions{JSON: true})
+
+	logsDir := t.TempDir()
+	runDir := filepath.Join(logsDir, "20260101-120000")
+	if err := os.MkdirAll(runDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runDir, "api.log"), []byte("hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{LogsDir: logsDir, RunMode: "timestamped"}
+	if err := cmdLs(cfg, "", nil); err != nil {
+		t.Fatalf("cmdLs() error = %v", err)
+	}
+
+	var got LsOutput
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	if got.LogsDir != logsDir || got.RunMode != "timestamped" || !got.Exists {
+		t.Errorf("unexpected header: %+v", got)
+	}
+	if len(got.Runs) != 1 || got.Runs[0].Name != "20260101-120000" || got.Runs[0].Files != 1 || got.Runs[0].Path != runDir {
+		t.Errorf("runs = %+v", got.Runs)
+	}
+}
+
+func TestCmdLs_JSONMissingDir(t *testing.T) {
+	stdout, _ := captureOutput(t, globalOptions{JSON: true})
+
+	cfg := &config.Config{LogsDir: filepath.Join(t.TempDir(), "missing"), RunMode: "timestamped"}
+	if err := cmdLs(cfg, "", nil); err != nil {
+		t.Fatalf("cmdLs() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"runs": []`) || !strings.Contains(stdout.String(), `"exists": false`) {
+		t.Errorf("output = %s", stdout)
+	}
+}
+
+func TestCmdStatus_JSONNotRunning(t *testing.T) {
+	stdout, _ := captureOutput(t, globalOptions{JSON: true})
+
+	cfg := &config.Config{
+		Project: "demo",
+		RunMode: "overwrite",
+		Tmux:    config.TmuxConfig{Session: "devlog-test-not-running-session"},
+	}
+	if err := cmdStatus(cfg, "", nil); err != nil {
+		t.Fatalf("cmdStatus() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, stdout)
+	}
+	want := map[string]any{"project": "demo", "session": "devlog-test-not-running-session", "run_mode": "overwrite", "running": false}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+	if _, ok := got["windows"]; ok {
+		t.Error("windows should be omitted when the session is not running")
+	}
+}
+
+func TestCmdHealthcheck_JSON(t *testing.T) {
+	stdout, _ := captureOutput(t, globalOptions{JSON: true})
+	t.Chdir(t.TempDir())
+
+	err := cmdHealthcheck(nil, "", nil)
+
+	var got HealthcheckOutput
+	if jsonErr := json.Unmarshal(stdout.Bytes(), &got); jsonErr != nil {
+		t.Fatalf("output is not JSON: %v\n%s", jsonErr, stdout)
+	}
+	if len(got.Checks) != 4 {
+		t.Errorf("got %d checks, want 4", len(got.Checks))
+	}
+	if got.OK != (err == nil) {
+		t.Errorf("ok = %v but err = %v", got.OK, err)
+	}
+	if err != nil {
+		if _, silent := err.(silentError); !silent || !strings.Contains(err.Error(), "healthcheck failed") {
+			t.Errorf("err = %#v, want silent healthcheck failure", err)
+		}
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	for name := range commands {
+		text, ok := commandHelp(name)
+		if !ok {
+			t.Errorf("no --help text for %s", name)
+			continue
+		}
+		if !strings.HasPrefix(text, "Usage: devlog "+name) || !strings.Contains(text, "--json") {
+			t.Errorf("%s help is missing its usage line or the global options:\n%s", name, text)
+		}
+	}
+	if _, ok := commandHelp("nope"); ok {
+		t.Error("commandHelp(nope) should fail")
+	}
+	for name := range jsonCommands {
+		if _, ok := commands[name]; !ok {
+			t.Errorf("jsonCommands lists unknown command %s", name)
+		}
+	}
+}
+
+func TestHasHelpFlag(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    bool
+	}{
+		{"up", nil, false},
+		{"up", []string{"--profile", "x"}, false},
+		{"up", []string{"--help"}, true},
+		{"up", []string{"--profile", "x", "-h"}, true},
+		{"up", []string{"--profile", "-h"}, false},
+		{"up", []string{"--", "--help"}, false},
+		{"pipe", []string{"--redact-pattern", "-h", "out.log"}, false},
+		{"pipe", []string{"--help"}, true},
+		{"unknown", []string{"--help"}, false},
+	}
+	for _, tt := range tests {
+		if got := hasHelpFlag(tt.command, tt.args); got != tt.want {
+			t.Errorf("hasHelpFlag(%q, %v) = %v, want %v", tt.command, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCommandFlagSpecs(t *testing.T) {
+	for name := range commands {
+		if _, ok := commandFlagSpecs[name]; !ok && name != "help" {
+			t.Errorf("command %s has no commandFlagSpecs entry", name)
+		}
+	}
+	for name := range commandFlagSpecs {
+		if _, ok := commands[name]; !ok {
+			t.Errorf("commandFlagSpecs lists unknown command %s", name)
+		}
+	}
+}
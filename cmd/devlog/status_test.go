@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/tmux"
 )
 
 func TestResolveStatusLogsDir_PrefersRunningLogsDir(t *testing.T) {
@@ -67,3 +68,42 @@ func TestEnsureFileExists(t *testing.T) {
 		t.Fatalf("expected file path, got directory: %s", target)
 	}
 }
+
+func TestResolveStatusLogsDir_RelativeToConfigDir(t *testing.T) {
+	configDir := t.TempDir()
+	cfg := &config.Config{
+		LogsDir: "./logs",
+		RunMode: "overwrite",
+		Dir:     configDir,
+	}
+	t.Chdir(t.TempDir())
+
+	got := resolveStatusLogsDir("", cfg)
+	want := filepath.Join(configDir, "logs")
+	if got != want {
+		t.Errorf("resolveStatusLogsDir() = %q, want %q", got, want)
+	}
+}
+
+func TestPaneStatus_State(t *testing.T) {
+	out = newOutput(globalOptions{NoColor: true})
+	defer func() { out = newOutput(globalOptions{}) }()
+
+	pane := PaneStatus{ID: "%1", Name: "api", Command: "bash"}
+	if got := pane.state(); got != "bash" {
+		t.Errorf("state() = %q, want the current command", got)
+	}
+
+	code := 137
+	exited := time.Date(2026, 1, 2, 14, 2, 0, 0, time.Local)
+	pane.setEvent(tmux.PaneEvent{Pane: "%1", Event: tmux.EventExit, Code: &code, Signal: 9, Time: exited})
+	if got, want := pane.state(), "exited 137 at 14:02 (signal 9)"; got != want {
+		t.Errorf("state() = %q, want %q", got, want)
+	}
+
+	// A command may exit 137 itself; only a recorded signal counts
+	pane.setEvent(tmux.PaneEvent{Pane: "%1", Event: tmux.EventExit, Code: &code, Time: exited})
+	if got, want := pane.state(), "exited 137 at 14:02"; got != want {
+		t.Errorf("state() for a plain exit 137 = %q, want %q", got, want)
+	}
+}
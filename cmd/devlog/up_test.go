@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+func TestCmdUp_PreUpFailureRemovesRunDir(t *testing.T) {
+	captureOutput(t, globalOptions{})
+
+	tests := []struct {
+		name    string
+		hook    string
+		wantDir bool
+	}{
+		{name: "hook log only", hook: "echo checking; exit 1", wantDir: false},
+		{name: "hook wrote to run dir", hook: "echo keep > \"$DEVLOG_LOGS_DIR/dump.txt\"; exit 1", wantDir: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logsDir := t.TempDir()
+			cfg := &config.Config{
+				Dir:     t.TempDir(),
+				LogsDir: logsDir,
+				RunMode: "timestamped",
+				Hooks:   config.HooksConfig{PreUp: config.StringList{tt.hook}},
+			}
+			cfg.Tmux.Session = fmt.Sprintf("devlog-test-preup-%d-%d", os.Getpid(), i)
+
+			err := cmdUp(cfg, "", nil)
+			if err == nil || !strings.Contains(err.Error(), "aborting up") {
+				t.Fatalf("cmdUp() error = %v, want aborting up", err)
+			}
+
+			entries, _ := os.ReadDir(logsDir)
+			if got := len(entries) > 0; got != tt.wantDir {
+				t.Errorf("run dir left behind = %v, want %v (entries %v)", got, tt.wantDir, entries)
+			}
+			if tt.wantDir {
+				if kept, _ := filepath.Glob(filepath.Join(logsDir, "*", "dump.txt")); len(kept) != 1 {
+					t.Errorf("hook output not kept: %v", kept)
+				}
+			}
+		})
+	}
+}
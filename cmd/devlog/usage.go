@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+const globalUsage = `Global options:
+  --config, -c <path>  Config file to use (default: DEVLOG_CONFIG, then
+                       devlog.yml, devlog.yaml, .devlog.yml or .devlog.yaml
+                       in the current or a parent directory)
+  --json               Print results as JSON (status, ls, healthcheck, up)
+  --quiet, -q          Only print errors and warnings
+  --no-color           Disable colored output (also NO_COLOR=1)
+  --verbose, -v        Echo every tmux command before it runs
+  --help, -h           Show help for a command
+`
+
+// jsonCommands lists the commands that support --json.
+var jsonCommands = map[string]bool{
+	"status":      true,
+	"ls":          true,
+	"healthcheck": true,
+	"up":          true,
+}
+
+// commandUsage is the --help text for each command; globalUsage is appended.
+var commandUsage = map[string]string{
+	"init": `Usage: devlog init
+
+Create a devlog.yml template in the current directory, or at the path given
+with --config.
+`,
+	"up": `Usage: devlog up [--profile <name>] [--dry-run] [--keep-on-failure]
+
+Run pre_up hooks, start the tmux session with every enabled pane (in
+depends_on order, waiting for readiness probes), set up browser logging and
+run post_up hooks.
+
+Options:
+  --profile <name>   Only start the windows/panes enabled by this profile
+  --dry-run          Print the plan (same as 'devlog plan') and change nothing
+  --keep-on-failure  Leave the session running when readiness probes fail
+                     (by default it is rolled back like any other failure)
+
+JSON output (--json): an UpOutput object, see README.
+`,
+	"plan": `Usage: devlog plan [--profile <name>]
+
+Print what 'devlog up' would do, without changing anything: old runs it would
+remove, hooks, the run directory and log files, every tmux command and the
+browser wrapper and manifest changes.
+
+Options:
+  --profile <name>   Plan for this profile
+`,
+	"down": `Usage: devlog down [--profile <name>]
+
+Run pre_down hooks, stop the tmux session, restore the browser host manifest
+and run post_down hooks.
+
+Options:
+  --profile <name>   Profile to use (default: the one the session started with)
+`,
+	"attach": `Usage: devlog attach
+
+Attach to the running tmux session.
+`,
+	"status": `Usage: devlog status [--profile <name>]
+
+Show session state, panes, log files and browser logging.
+
+Options:
+  --profile <name>   Profile to use (default: the one the session started with)
+
+JSON output (--json): a StatusOutput object, see README.
+`,
+	"ls": `Usage: devlog ls
+
+List log runs in the logs directory.
+
+JSON output (--json): an LsOutput object, see README.
+`,
+	"config": `Usage: devlog config
+
+Show the merged config files and the file each value came from.
+`,
+	"validate": `Usage: devlog validate [options] [path]
+
+Check devlog.yml (plus extends: bases and devlog.local.yml) for syntax errors,
+unknown keys, wrong value types and missing required fields.
+
+Options:
+  --schema     Print the JSON Schema for devlog.yml and exit
+
+Examples:
+  devlog validate
+  devlog validate devlog.e2e.yml
+  devlog validate --schema > devlog.schema.json
+`,
+	"migrate": `Usage: devlog migrate [options] [path]
+
+Upgrade devlog.yml to config version ` + config.CurrentVersion + ` in place, keeping comments.
+
+Options:
+  --dry-run    Print the migrated file instead of writing it
+`,
+	"open": `Usage: devlog open
+
+Open the current run's logs directory in the file manager.
+`,
+	"register": `Usage: devlog register [options]
+
+Register native messaging host for browser logging.
+
+Options:
+  --chrome         Register for Google Chrome
+  --brave          Register for Brave Browser
+  --firefox        Register for Mozilla Firefox
+  --extension-id   Chrome/Brave extension ID (required for --chrome or --brave)
+
+Examples:
+  devlog register --chrome --extension-id abcdefghijklmnopqrstuvwxyz123456
+  devlog register --brave --extension-id abcdefghijklmnopqrstuvwxyz123456
+  devlog register --firefox
+  devlog register --chrome --brave --extension-id abcdefghijklmnopqrstuvwxyz123456
+`,
+	"healthcheck": `Usage: devlog healthcheck
+
+Check that tmux, the devlog-host binary and the browser extension
+registration are in place.
+
+JSON output (--json): a HealthcheckOutput object, see README.
+`,
+	"pipe": `Usage: devlog pipe [options] <file>
+
+Copy pane output from stdin to file, appending. devlog up sets this up with
+tmux pipe-pane for panes with a timestamps, sanitize, raw_log or
+max_file_size option, and for every pane log when redact rules are
+configured; you don't run it yourself.
+
+Options:
+  --timestamps <format>     Prefix every line: none (default), rfc3339 or relative
+  --start <time>            Run start (RFC 3339) that relative timestamps count from
+  --sanitize                Strip escape sequences and apply \r and backspace redraws
+  --raw <file>              Also append the output, unprocessed, to this file
+  --redact-preset <name>    Mask a built-in kind of secret; repeatable
+  --redact-pattern <regex>  Mask matches of a regular expression; repeatable
+  --max-file-size <bytes>   Rotate the files at this size (file.1, file.2, ...)
+  --max-files <n>           Rotated files kept (default 5)
+  --compress                Gzip rotated files
+`,
+	"run": `Usage: devlog run [options] -- <command>
+
+Run a pane's command with sh, then start your shell ($SHELL) in its place
+so the pane stays open. devlog up starts every pane with it (tmux
+respawn-pane); you don't run it yourself.
+
+Options:
+  --events <file>           Append the command's start and exit events to this file
+  --name <name>             Pane name recorded with the events
+  --label <label>           Pane label used in restart markers
+  --env <NAME=VALUE>        Set an environment variable; repeatable
+  --restart <policy>        Restart policy: never (default), on-failure or always
+  --max-retries <n>         Restarts before giving up (default 0, no limit)
+  --backoff <duration>      Delay before the first restart, doubled after each (default 1s)
+`,
+	"help": `Usage: devlog help [command]
+
+Show general help, or the help for a command.
+`,
+}
+
+// commandHelp returns the --help text for command.
+func commandHelp(command string) (string, bool) {
+	text, ok := commandUsage[command]
+	if !ok {
+		return "", false
+	}
+	return strings.TrimRight(text, "\n") + "\n\n" + globalUsage, true
+}
+
+// cmdHelp prints the general usage, or a command's help with `devlog help <command>`.
+func cmdHelp(cfg *config.Config, configPath string, args []string) error {
+	if len(args) > 0 {
+		text, ok := commandHelp(args[0])
+		if !ok {
+			return fmt.Errorf("unknown command: %s", args[0])
+		}
+		fmt.Print(text)
+		return nil
+	}
+	fmt.Print(usage)
+	return nil
+}
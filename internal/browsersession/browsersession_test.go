@@ -2,6 +2,7 @@ package browsersession
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -268,3 +269,91 @@ func TestRefuseClobberActiveWrapper_OtherLiveSession(t *testing.T) {
 		t.Errorf("error = %q, want already in use", err.Error())
 	}
 }
+
+func TestPlanStart_DoesNotChangeFiles(t *testing.T) {
+	_, cleanup := withIsolatedHome(t)
+	defer cleanup()
+
+	tmp := t.TempDir()
+	hostPath := filepath.Join(tmp, "devlog-host")
+	if err := os.WriteFile(hostPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.InstallChromeManifest(hostPath, "testid"); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	bs := newTestSession(hostPath)
+	lines, err := bs.PlanStart("plan-session", filepath.Join(tmp, "browser.log"), []string{"error"})
+	if err != nil {
+		t.Fatalf("PlanStart() error = %v", err)
+	}
+
+	wrapperPath := browserHostWrapperPath("plan-session")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "write "+wrapperPath) || !strings.Contains(lines[1], "(currently "+hostPath+")") {
+		t.Errorf("PlanStart() = %q", lines)
+	}
+	if _, err := os.Stat(wrapperPath); !os.IsNotExist(err) {
+		t.Error("PlanStart() must not write the wrapper")
+	}
+	if got := readChromePath(t); got != hostPath {
+		t.Errorf("manifest path = %q, want it unchanged (%q)", got, hostPath)
+	}
+}
+
+// failingUpdateManifest updates the manifests to the wrapper but then reports
+// an error, like a failure on the second of several manifests.
+type failingUpdateManifest struct {
+	fixedHostManifest
+}
+
+func (f failingUpdateManifest) UpdateManifestPath(newPath string) error {
+	if err := manifest.UpdateManifestPath(newPath); err != nil {
+		return err
+	}
+	if newPath != f.hostPath {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+func TestStart_RestoresManifestsOnFailure(t *testing.T) {
+	_, cleanup := withIsolatedHome(t)
+	defer cleanup()
+
+	tmp := t.TempDir()
+	hostPath := filepath.Join(tmp, "devlog-host")
+	if err := os.WriteFile(hostPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.InstallChromeManifest(hostPath, "testid"); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	bs := New(failingUpdateManifest{fixedHostManifest{hostPath: hostPath}}, realSessionChecker{})
+	if err := bs.Start("fail-session", filepath.Join(tmp, "browser.log"), nil); err == nil {
+		t.Fatal("Start() should fail")
+	}
+
+	if got := readChromePath(t); got != hostPath {
+		t.Errorf("manifest path = %q, want it restored to %q", got, hostPath)
+	}
+	if _, err := os.Stat(browserHostWrapperPath("fail-session")); !os.IsNotExist(err) {
+		t.Error("wrapper should be removed after a failed Start")
+	}
+}
+
+func TestStart_HostNotFound(t *testing.T) {
+	bs := New(missingHostManifest{}, realSessionChecker{})
+	if err := bs.Start("s", "browser.log", nil); !errors.Is(err, ErrHostNotFound) {
+		t.Errorf("Start() error = %v, want ErrHostNotFound", err)
+	}
+}
+
+type missingHostManifest struct {
+	fixedHostManifest
+}
+
+func (missingHostManifest) FindDevlogHostBinary() (string, error) {
+	return "", errors.New("not on PATH")
+}
@@ -79,5 +79,8 @@ func TestBatchQuote(t *testing.T) {
 	if batchQuote(`say "hi"`) != `"say ""hi"""` {
 		t.Errorf("batchQuote quotes = %q", batchQuote(`say "hi"`))
 	}
+	if batchQuote(`100%\d`) != `"100%%\d"` {
+		t.Errorf("batchQuote percent = %q", batchQuote(`100%\d`))
+	}
 }
 
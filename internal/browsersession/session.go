@@ -4,10 +4,12 @@
 package browsersession
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/jellydn/devlog/internal/shellescape"
@@ -52,14 +54,73 @@ type HealthResult struct {
 	StalePaths    int
 }
 
+// ErrHostNotFound is returned (wrapped) by Start when the devlog-host binary
+// is not installed, so browser logging cannot be set up at all.
+var ErrHostNotFound = errors.New("devlog-host binary not found")
+
+// hostNotFoundError keeps the lookup error's message while matching
+// ErrHostNotFound with errors.Is.
+type hostNotFoundError struct {
+	err error
+}
+
+func (e hostNotFoundError) Error() string   { return e.err.Error() }
+func (e hostNotFoundError) Unwrap() []error { return []error{ErrHostNotFound, e.err} }
+
 // Start creates the native messaging wrapper script, guards against clobbering
 // an active session's wrapper, and updates all installed manifests to point at it.
-func (s *Session) Start(sessionName, browserLogPath string, levels []string) error {
+// If updating the manifests fails, they are restored to the devlog-host binary
+// and the wrapper is removed. hostArgs follow the log path on the devlog-host
+// command line: redact flags and log levels.
+func (s *Session) Start(sessionName, browserLogPath string, hostArgs []string) error {
 	hostPath, err := s.manifest.FindDevlogHostBinary()
 	if err != nil {
-		return err
+		return hostNotFoundError{err}
+	}
+	return s.start(sessionName, browserLogPath, hostArgs, hostPath)
+}
+
+// PlanStart describes the changes Start would make, one line per file,
+// without touching anything.
+func (s *Session) PlanStart(sessionName, browserLogPath string, hostArgs []string) ([]string, error) {
+	hostPath, err := s.manifest.FindDevlogHostBinary()
+	if err != nil {
+		return nil, err
+	}
+	absLogPath, err := filepath.Abs(browserLogPath)
+	if err != nil {
+		return nil, err
+	}
+	wrapperPath := browserHostWrapperPath(sessionName)
+	if err := s.refuseClobberActiveWrapper(wrapperPath); err != nil {
+		return nil, err
+	}
+
+	script := generateShellScript(hostPath, absLogPath, hostArgs)
+	if runtime.GOOS == "windows" {
+		script = generateBatchScript(hostPath, absLogPath, hostArgs)
 	}
-	return s.start(sessionName, browserLogPath, levels, hostPath)
+	lines := []string{fmt.Sprintf("write %s: %s", wrapperPath, strings.TrimSpace(scriptCommand(script)))}
+
+	paths, _ := s.manifest.ReadManifestPaths()
+	manifests := make([]string, 0, len(paths))
+	for manifestPath := range paths {
+		manifests = append(manifests, manifestPath)
+	}
+	sort.Strings(manifests)
+	for _, manifestPath := range manifests {
+		lines = append(lines, fmt.Sprintf("point %s at the wrapper (currently %s)", manifestPath, paths[manifestPath]))
+	}
+	if len(manifests) == 0 {
+		lines = append(lines, "no native messaging manifests installed; run 'devlog register' first")
+	}
+	return lines, nil
+}
+
+// scriptCommand returns the last line of a wrapper script: the host command.
+func scriptCommand(script string) string {
+	lines := strings.Split(strings.TrimSpace(script), "\n")
+	return lines[len(lines)-1]
 }
 
 // Stop restores manifests to point at the real devlog-host binary and removes
@@ -124,7 +185,7 @@ func (s *Session) HealthCheck() (*HealthResult, error) {
 	return result, nil
 }
 
-func (s *Session) start(session, browserLogPath string, levels []string, hostPath string) error {
+func (s *Session) start(session, browserLogPath string, hostArgs []string, hostPath string) error {
 	if err := s.manifest.ValidateHostPath(hostPath); err != nil {
 		return fmt.Errorf("untrusted host binary: %w", err)
 	}
@@ -155,15 +216,17 @@ func (s *Session) start(session, browserLogPath string, levels []string, hostPat
 
 	var script string
 	if runtime.GOOS == "windows" {
-		script = generateBatchScript(hostPath, absLogPath, levels)
+		script = generateBatchScript(hostPath, absLogPath, hostArgs)
 	} else {
-		script = generateShellScript(hostPath, absLogPath, levels)
+		script = generateShellScript(hostPath, absLogPath, hostArgs)
 	}
 	if err := os.WriteFile(wrapperPath, []byte(script), 0700); err != nil {
 		return err
 	}
 
 	if err := s.manifest.UpdateManifestPath(wrapperPath); err != nil {
+		// Some manifests may already point at the wrapper; put them back
+		s.stop(session, hostPath)
 		return fmt.Errorf("failed to update native messaging manifest: %w", err)
 	}
 
@@ -280,28 +343,30 @@ func sessionFromWrapperPath(path string) (string, bool) {
 	return name, true
 }
 
-func generateShellScript(hostPath, absLogPath string, levels []string) string {
+func generateShellScript(hostPath, absLogPath string, hostArgs []string) string {
 	// Build script with proper shell escaping. exec replaces the shell with the host.
 	var scriptArgs []string
 	scriptArgs = append(scriptArgs, shellescape.Quote(hostPath), shellescape.Quote(absLogPath))
-	for _, level := range levels {
-		scriptArgs = append(scriptArgs, shellescape.Quote(level))
+	for _, arg := range hostArgs {
+		scriptArgs = append(scriptArgs, shellescape.Quote(arg))
 	}
 	return fmt.Sprintf("#!/bin/sh\nexec %s\n", strings.Join(scriptArgs, " "))
 }
 
-func generateBatchScript(hostPath, absLogPath string, levels []string) string {
+func generateBatchScript(hostPath, absLogPath string, hostArgs []string) string {
 	// Native messaging on Windows can invoke a .bat host wrapper.
 	var scriptArgs []string
 	scriptArgs = append(scriptArgs, batchQuote(hostPath), batchQuote(absLogPath))
-	for _, level := range levels {
-		scriptArgs = append(scriptArgs, batchQuote(level))
+	for _, arg := range hostArgs {
+		scriptArgs = append(scriptArgs, batchQuote(arg))
 	}
 	return "@echo off\r\n" + strings.Join(scriptArgs, " ") + "\r\n"
 }
 
 // batchQuote returns a Windows batch-escaped argument using double quotes.
-// Embedded double quotes are escaped by doubling them.
+// Embedded double quotes and percent signs (e.g. in redact patterns) are
+// escaped by doubling them.
 func batchQuote(s string) string {
-	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	s = strings.ReplaceAll(s, `"`, `""`)
+	return `"` + strings.ReplaceAll(s, `%`, `%%`) + `"`
 }
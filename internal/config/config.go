@@ -2,41 +2,265 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/jellydn/devlog/internal/logger"
+	"github.com/jellydn/devlog/internal/logrotate"
+	"github.com/jellydn/devlog/internal/redact"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the devlog.yml configuration
 type Config struct {
-	Version       string        `yaml:"version"`
-	Project       string        `yaml:"project"`
-	LogsDir       string        `yaml:"logs_dir"`
-	RunMode       string        `yaml:"run_mode"`
-	MaxRuns       int           `yaml:"max_runs"`
-	RetentionDays int           `yaml:"retention_days"`
-	Tmux          TmuxConfig    `yaml:"tmux"`
-	Browser       BrowserConfig `yaml:"browser"`
+	Version       string                   `yaml:"version"`
+	Project       string                   `yaml:"project"`
+	LogsDir       string                   `yaml:"logs_dir"`
+	RunMode       string                   `yaml:"run_mode"`
+	MaxRuns       int                      `yaml:"max_runs"`
+	RetentionDays int                      `yaml:"retention_days"`
+	StrictEnv     bool                     `yaml:"strict_env"` // error on unset variables instead of keeping them literal
+	EnvFile       StringList               `yaml:"env_file"`   // dotenv files used for interpolation and every pane's env
+	Tmux          TmuxConfig               `yaml:"tmux"`
+	Browser       BrowserConfig            `yaml:"browser"`
+	Hooks         HooksConfig              `yaml:"hooks"`
+	Shutdown      ShutdownConfig           `yaml:"shutdown"`
+	Redact        RedactConfig             `yaml:"redact"`
+	Profiles      map[string]ProfileConfig `yaml:"profiles"`
+
+	// Profile is the name of the profile applied by ApplyProfile, if any.
+	Profile string `yaml:"-"`
+
+	// Dir is the absolute directory containing the loaded config file.
+	// Relative paths in the config are resolved against it. Empty for
+	// configs built in code rather than loaded from disk.
+	Dir string `yaml:"-"`
+
+	// Files lists the config files that were merged, base first.
+	Files []string `yaml:"-"`
+
+	// Sources records the file each leaf value came from, in document order.
+	Sources []ValueSource `yaml:"-"`
+
+	// MigratedFrom is the older schema version the file declared, when Load
+	// upgraded it in memory. Empty when the file is already current.
+	MigratedFrom string `yaml:"-"`
 }
 
 // TmuxConfig represents tmux session configuration
 type TmuxConfig struct {
 	Session string         `yaml:"session"`
+	Socket  string         `yaml:"socket"` // server socket name (-L) or path (-S); see Config.TmuxSocket
+	Config  string         `yaml:"config"` // tmux.conf the devlog server starts with (-f)
 	Windows []WindowConfig `yaml:"windows"`
 }
 
-// WindowConfig represents a tmux window
+// DefaultTmuxSocket is the tmux.socket value that selects the user's own
+// tmux server instead of a devlog one.
+const DefaultTmuxSocket = "default"
+
+// SessionSocketName is the socket name of the tmux server devlog runs a
+// session on when tmux.socket is not set: one server per project session,
+// so `tmux kill-server` and personal sessions don't affect it.
+func SessionSocketName(session string) string {
+	var b strings.Builder
+	for _, r := range session {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return "devlog-" + b.String()
+}
+
+// WindowConfig represents a tmux window.
+// Cwd and Env apply to every pane in the window unless a pane overrides them.
 type WindowConfig struct {
-	Name  string       `yaml:"name"`
-	Panes []PaneConfig `yaml:"panes"`
+	Name   string            `yaml:"name"`
+	Cwd    string            `yaml:"cwd"`
+	Env    map[string]string `yaml:"env"`
+	Layout string            `yaml:"layout"` // tmux layout name or raw layout string, applied after all panes exist
+	Panes  []PaneConfig      `yaml:"panes"`
 }
 
 // PaneConfig represents a tmux pane
 type PaneConfig struct {
-	Cmd string `yaml:"cmd"`
-	Log string `yaml:"log"`
+	Name    string            `yaml:"name"`
+	Cmd     string            `yaml:"cmd"`
+	Log     string            `yaml:"log"`
+	Cwd     string            `yaml:"cwd"`
+	Env     map[string]string `yaml:"env"`
+	EnvFile StringList        `yaml:"env_file"` // dotenv files loaded into this pane's env
+	Split   string            `yaml:"split"`    // "h" (side by side, default) or "v" (stacked)
+	Size    int               `yaml:"size"`     // size of the new pane as a percentage of the split pane
+
+	Timestamps string `yaml:"timestamps"` // prefix for log lines: "none" (default), "rfc3339" or "relative"
+	Sanitize   bool   `yaml:"sanitize"`   // strip escape sequences and apply \r and backspace redraws
+	RawLog     string `yaml:"raw_log"`    // unprocessed copy of the output, next to log
+
+	MaxFileSize string `yaml:"max_file_size"` // rotate log (and raw_log) at this size, e.g. "10MB"
+	MaxFiles    int    `yaml:"max_files"`     // rotated files kept (default 5)
+	Compress    bool   `yaml:"compress"`      // gzip rotated files
+
+	DependsOn StringList  `yaml:"depends_on"` // pane names that must be ready before this pane starts
+	Ready     ReadyConfig `yaml:"ready"`      // readiness probe for panes that depend on this one
+
+	Restart    string `yaml:"restart"`     // "never" (default), "on-failure" or "always"
+	MaxRetries int    `yaml:"max_retries"` // restarts before giving up; 0 means no limit
+	Backoff    string `yaml:"backoff"`     // delay before the first restart, doubled each time (default 1s)
+}
+
+// Split directions for PaneConfig.Split.
+const (
+	SplitHorizontal = "h"
+	SplitVertical   = "v"
+)
+
+// layoutNames are the tmux preset layouts accepted by WindowConfig.Layout.
+var layoutNames = []string{"tiled", "even-horizontal", "even-vertical", "main-horizontal", "main-vertical"}
+
+// rawLayoutRegex matches a tmux layout string as printed by #{window_layout},
+// e.g. "b25d,158x40,0,0{79x40,0,0,1,78x40,80,0,2}".
+var rawLayoutRegex = regexp.MustCompile(`^[0-9a-f]{4},\d+x\d+,\d+,\d+`)
+
+// Log line timestamps for PaneConfig.Timestamps.
+const (
+	TimestampsNone     = "none"
+	TimestampsRFC3339  = "rfc3339"  // 2026-01-02T15:04:05.000+01:00
+	TimestampsRelative = "relative" // +12.345s since the run started
+)
+
+// Restart policies for PaneConfig.Restart.
+const (
+	RestartNever     = "never"
+	RestartOnFailure = "on-failure"
+	RestartAlways    = "always"
+)
+
+// Default and maximum delay between pane restarts.
+const (
+	DefaultRestartBackoff = time.Second
+	MaxRestartBackoff     = time.Minute
+)
+
+// Supervised reports whether the pane is restarted when its command exits.
+func (p PaneConfig) Supervised() bool {
+	return p.Restart == RestartOnFailure || p.Restart == RestartAlways
+}
+
+// Piped reports whether the pane's log goes through `devlog pipe` rather
+// than straight to the file.
+func (p PaneConfig) Piped() bool {
+	if p.Log == "" {
+		return false
+	}
+	return (p.Timestamps != "" && p.Timestamps != TimestampsNone) || p.Sanitize || p.RawLog != "" || p.MaxFileSize != ""
+}
+
+// LogRotation returns the size-based rotation of the pane's log files.
+func (p PaneConfig) LogRotation() logrotate.FileOptions {
+	return logRotation(p.MaxFileSize, p.MaxFiles, p.Compress)
+}
+
+// BackoffDuration returns the delay before the first restart, or DefaultRestartBackoff.
+func (p PaneConfig) BackoffDuration() time.Duration {
+	if d, err := time.ParseDuration(p.Backoff); err == nil && d > 0 {
+		return d
+	}
+	return DefaultRestartBackoff
+}
+
+// PaneCwd returns the working directory for pane, falling back to the window's cwd.
+func (w WindowConfig) PaneCwd(pane PaneConfig) string {
+	if pane.Cwd != "" {
+		return pane.Cwd
+	}
+	return w.Cwd
+}
+
+// PaneEnv returns the window env merged with pane env (pane values win).
+// Returns nil when neither defines any variables.
+func (w WindowConfig) PaneEnv(pane PaneConfig) map[string]string {
+	return mergeEnv(w.Env, pane.Env)
+}
+
+// ProfileConfig selects a subset of windows/panes and overrides pane env.
+// Names refer to window names or pane names.
+type ProfileConfig struct {
+	Enable  []string          `yaml:"enable"`  // when set, only these windows/panes run
+	Disable []string          `yaml:"disable"` // windows/panes that never run in this profile
+	Env     map[string]string `yaml:"env"`     // applied to every pane, overriding window/pane env
+}
+
+// HooksConfig lists shell commands run around devlog up and devlog down.
+// Each command runs with sh -c from the config directory.
+type HooksConfig struct {
+	PreUp    StringList `yaml:"pre_up"`    // before the session starts; a failure aborts up
+	PostUp   StringList `yaml:"post_up"`   // after the session and browser logging are set up
+	PreDown  StringList `yaml:"pre_down"`  // before the session is stopped
+	PostDown StringList `yaml:"post_down"` // after the session is stopped
+}
+
+// ShutdownConfig controls how devlog down stops pane commands: each signal is
+// sent to the process groups running in every pane still busy, then devlog
+// waits up to Timeout for them to exit before sending the next one.
+type ShutdownConfig struct {
+	Signals StringList `yaml:"signals"` // e.g. [INT, TERM, KILL] (the default)
+	Timeout string     `yaml:"timeout"` // wait after each signal (default 5s)
+}
+
+// DefaultShutdownSignals is the signal sequence when shutdown.signals is unset.
+var DefaultShutdownSignals = []string{"INT", "TERM", "KILL"}
+
+// DefaultShutdownTimeout is the wait after each signal when shutdown.timeout is unset.
+const DefaultShutdownTimeout = 5 * time.Second
+
+// shutdownSignals are the signal names accepted in shutdown.signals.
+var shutdownSignals = []string{"INT", "TERM", "KILL", "HUP", "QUIT", "USR1", "USR2"}
+
+// SignalNames returns the signal sequence without SIG prefixes, in upper
+// case, or DefaultShutdownSignals.
+func (s ShutdownConfig) SignalNames() []string {
+	if len(s.Signals) == 0 {
+		return DefaultShutdownSignals
+	}
+	names := make([]string, len(s.Signals))
+	for i, name := range s.Signals {
+		names[i] = normalizeSignal(name)
+	}
+	return names
+}
+
+// TimeoutDuration returns the wait after each signal, or DefaultShutdownTimeout.
+func (s ShutdownConfig) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(s.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultShutdownTimeout
+}
+
+func normalizeSignal(name string) string {
+	return strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(name)), "SIG")
+}
+
+// RedactConfig lists the secrets masked with "[REDACTED]" in pane and browser
+// logs before they are written.
+type RedactConfig struct {
+	Presets  StringList `yaml:"presets"`  // built-in rules, see redact.PresetNames
+	Patterns StringList `yaml:"patterns"` // extra regular expressions; only the first group is masked when there is one
+}
+
+// Enabled reports whether any rule is configured.
+func (r RedactConfig) Enabled() bool {
+	return len(r.Presets) > 0 || len(r.Patterns) > 0
 }
 
 // BrowserConfig represents browser log capture configuration
@@ -44,22 +268,92 @@ type BrowserConfig struct {
 	URLs   []string `yaml:"urls"`
 	File   string   `yaml:"file"`
 	Levels []string `yaml:"levels"`
+
+	Format   string `yaml:"format"`   // "text" (default), "jsonl" or "logfmt"
+	Template string `yaml:"template"` // Go text/template for text lines, e.g. "{{.Level}} {{.Message}}"
+
+	MaxFileSize string `yaml:"max_file_size"` // rotate the browser log at this size, e.g. "10MB"
+	MaxFiles    int    `yaml:"max_files"`     // rotated files kept (default 5)
+	Compress    bool   `yaml:"compress"`      // gzip rotated files
+}
+
+// FormatOptions returns how the browser log is written.
+func (b BrowserConfig) FormatOptions() logger.FormatOptions {
+	return logger.FormatOptions{Format: b.Format, Template: b.Template}
+}
+
+// LogRotation returns the size-based rotation of the browser log.
+func (b BrowserConfig) LogRotation() logrotate.FileOptions {
+	return logRotation(b.MaxFileSize, b.MaxFiles, b.Compress)
+}
+
+func logRotation(maxFileSize string, maxFiles int, compress bool) logrotate.FileOptions {
+	size, _ := ParseSize(maxFileSize)
+	return logrotate.FileOptions{MaxSize: size, MaxFiles: maxFiles, Compress: compress}
+}
+
+// sizeUnits are the suffixes accepted by ParseSize, longest first.
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30}, {"B", 1},
+}
+
+// ParseSize parses a byte size like "512KB", "10MB" or "1G" (binary
+// multiples, case-insensitive); a bare number is bytes. Empty means 0.
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 || n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("invalid size")
+	}
+	return n * multiplier, nil
 }
 
-// Load reads and parses the devlog.yml file
+// Load reads and parses the devlog.yml file, merged on top of any extends:
+// bases and overlaid with devlog.local.yml when it exists next to path.
 func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
+	loader := newLayerLoader()
+	merged, err := loader.loadLayers(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
+	}
+
+	cfg, err := decodeMerged(path, loader, merged)
+	if err != nil {
+		return nil, err
 	}
 
-	// Interpolate environment variables
-	interpolated := interpolateEnvVars(string(data))
+	// Validate
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
 
+// decodeMerged decodes the merged YAML tree, applies defaults and resolves
+// paths and env files. It does not validate.
+func decodeMerged(path string, loader *layerLoader, merged *yaml.Node) (*Config, error) {
 	var cfg Config
-	if err := yaml.Unmarshal([]byte(interpolated), &cfg); err != nil {
+	if err := merged.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	cfg.Files = loader.files
+	cfg.Sources = loader.sources(merged, "", nil)
+	cfg.MigratedFrom = loader.migratedFrom
 
 	// Apply defaults
 	if cfg.LogsDir == "" {
@@ -69,70 +363,405 @@ func Load(path string) (*Config, error) {
 		cfg.RunMode = "timestamped"
 	}
 
-	// Validate
-	if err := cfg.Validate(); err != nil {
+	// Resolve pane/window working directories relative to the config file
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	cfg.Dir = filepath.Dir(absPath)
+	cfg.resolveCwds(cfg.Dir)
+
+	// Feed env_file values into pane environments
+	if err := cfg.applyEnvFiles(loader.dotenv); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
 
+// FieldError is a validation problem tied to a config key path
+// (e.g. "tmux.windows[1].panes[0].cmd").
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("config: %s %s", e.Path, e.Message)
+}
+
 // Validate checks that all required fields are present and valid
 func (c *Config) Validate() error {
+	if problems := c.Problems(); len(problems) > 0 {
+		return problems[0]
+	}
+	return nil
+}
+
+// Problems returns every validation problem, in the order Validate reports them.
+func (c *Config) Problems() []FieldError {
+	var problems []FieldError
+	add := func(path, format string, args ...any) {
+		problems = append(problems, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
 	if c.Version == "" {
-		return fmt.Errorf("config: version is required")
+		add("version", "is required")
+	} else if !supportedVersion(c.Version) {
+		add("version", "'%s' is not supported (current version is %s)", c.Version, CurrentVersion)
 	}
 	if c.Project == "" {
-		return fmt.Errorf("config: project is required")
+		add("project", "is required")
 	}
 	if c.Tmux.Session == "" {
-		return fmt.Errorf("config: tmux.session is required")
+		add("tmux.session", "is required")
+	}
+	if c.Tmux.Config != "" {
+		if _, err := os.Stat(c.ResolveTmuxConfig()); err != nil {
+			add("tmux.config", "file '%s' does not exist", c.Tmux.Config)
+		}
 	}
 	if len(c.Tmux.Windows) == 0 {
-		return fmt.Errorf("config: tmux.windows must have at least one window")
+		add("tmux.windows", "must have at least one window")
 	}
+	paneNames := make(map[string]bool)
 	for i, window := range c.Tmux.Windows {
 		if len(window.Panes) == 0 {
-			return fmt.Errorf("config: tmux.windows[%d].panes must have at least one pane", i)
+			add(fmt.Sprintf("tmux.windows[%d].panes", i), "must have at least one pane")
+		}
+		problems = append(problems, validateEnv(fmt.Sprintf("tmux.windows[%d].env", i), window.Env)...)
+		if window.Layout != "" && !slices.Contains(layoutNames, window.Layout) && !rawLayoutRegex.MatchString(window.Layout) {
+			add(fmt.Sprintf("tmux.windows[%d].layout", i), "must be one of %s or a tmux layout string, got '%s'", strings.Join(layoutNames, ", "), window.Layout)
 		}
 		for j, pane := range window.Panes {
 			if pane.Cmd == "" {
-				return fmt.Errorf("config: tmux.windows[%d].panes[%d].cmd is required", i, j)
+				add(fmt.Sprintf("tmux.windows[%d].panes[%d].cmd", i, j), "is required")
+			}
+			if pane.Name != "" {
+				if paneNames[pane.Name] {
+					add(fmt.Sprintf("tmux.windows[%d].panes[%d].name", i, j), "'%s' is already used by another pane", pane.Name)
+				}
+				paneNames[pane.Name] = true
+			}
+			problems = append(problems, validateEnv(fmt.Sprintf("tmux.windows[%d].panes[%d].env", i, j), pane.Env)...)
+			if pane.Split != "" && pane.Split != SplitHorizontal && pane.Split != SplitVertical {
+				add(fmt.Sprintf("tmux.windows[%d].panes[%d].split", i, j), "must be 'h' or 'v', got '%s'", pane.Split)
+			}
+			switch pane.Timestamps {
+			case "", TimestampsNone, TimestampsRFC3339, TimestampsRelative:
+			default:
+				add(fmt.Sprintf("tmux.windows[%d].panes[%d].timestamps", i, j), "must be 'none', 'rfc3339' or 'relative', got '%s'", pane.Timestamps)
+			}
+			problems = append(problems, validateRotation(fmt.Sprintf("tmux.windows[%d].panes[%d]", i, j), pane.MaxFileSize, pane.MaxFiles)...)
+			if pane.RawLog != "" {
+				if pane.Log == "" {
+					add(fmt.Sprintf("tmux.windows[%d].panes[%d].raw_log", i, j), "requires log")
+				} else if pane.RawLog == pane.Log {
+					add(fmt.Sprintf("tmux.windows[%d].panes[%d].raw_log", i, j), "must differ from log")
+				}
+			}
+			if pane.Size != 0 && (pane.Size < 1 || pane.Size > 99) {
+				add(fmt.Sprintf("tmux.windows[%d].panes[%d].size", i, j), "must be a percentage between 1 and 99, got %d", pane.Size)
+			}
+			problems = append(problems, validateRestart(fmt.Sprintf("tmux.windows[%d].panes[%d]", i, j), pane)...)
+		}
+	}
+	problems = append(problems, c.validateReadiness()...)
+	for _, stage := range []struct {
+		key      string
+		commands StringList
+	}{{"pre_up", c.Hooks.PreUp}, {"post_up", c.Hooks.PostUp}, {"pre_down", c.Hooks.PreDown}, {"post_down", c.Hooks.PostDown}} {
+		for k, command := range stage.commands {
+			if strings.TrimSpace(command) == "" {
+				add(fmt.Sprintf("hooks.%s[%d]", stage.key, k), "must not be empty")
 			}
 		}
 	}
+	for k, signal := range c.Shutdown.Signals {
+		if !slices.Contains(shutdownSignals, normalizeSignal(signal)) {
+			add(fmt.Sprintf("shutdown.signals[%d]", k), "must be one of %s, got '%s'", strings.Join(shutdownSignals, ", "), signal)
+		}
+	}
+	if c.Shutdown.Timeout != "" {
+		if d, err := time.ParseDuration(c.Shutdown.Timeout); err != nil || d <= 0 {
+			add("shutdown.timeout", "must be a positive duration like '5s', got '%s'", c.Shutdown.Timeout)
+		}
+	}
+	if c.Browser.Format != "" && !slices.Contains(logger.Formats, c.Browser.Format) {
+		add("browser.format", "must be one of %s, got '%s'", strings.Join(logger.Formats, ", "), c.Browser.Format)
+	} else if c.Browser.Template != "" {
+		if c.Browser.Format != "" && c.Browser.Format != logger.FormatText {
+			add("browser.template", "requires format '%s', got '%s'", logger.FormatText, c.Browser.Format)
+		} else if _, err := logger.ParseTemplate(c.Browser.Template); err != nil {
+			add("browser.template", "%v", err)
+		}
+	}
+	problems = append(problems, validateRotation("browser", c.Browser.MaxFileSize, c.Browser.MaxFiles)...)
+	for k, preset := range c.Redact.Presets {
+		if !redact.IsPreset(preset) {
+			add(fmt.Sprintf("redact.presets[%d]", k), "must be one of %s, got '%s'", strings.Join(redact.PresetNames(), ", "), preset)
+		}
+	}
+	for k, pattern := range c.Redact.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			add(fmt.Sprintf("redact.patterns[%d]", k), "must be a valid regular expression: %v", err)
+		}
+	}
+	problems = append(problems, c.validateProfiles()...)
 	if c.RunMode != "timestamped" && c.RunMode != "overwrite" {
-		return fmt.Errorf("config: run_mode must be 'timestamped' or 'overwrite', got '%s'", c.RunMode)
+		add("run_mode", "must be 'timestamped' or 'overwrite', got '%s'", c.RunMode)
 	}
 	if c.MaxRuns < 0 {
-		return fmt.Errorf("config: max_runs must be non-negative, got %d", c.MaxRuns)
+		add("max_runs", "must be non-negative, got %d", c.MaxRuns)
 	}
 	if c.RetentionDays < 0 {
-		return fmt.Errorf("config: retention_days must be non-negative, got %d", c.RetentionDays)
+		add("retention_days", "must be non-negative, got %d", c.RetentionDays)
 	}
+	return problems
+}
+
+// ApplyProfile narrows Tmux.Windows to the panes enabled by the named profile
+// and merges the profile env into every remaining pane. An empty name is a no-op.
+func (c *Config) ApplyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("config: profile '%s' is not defined", name)
+	}
+
+	enabled := toSet(profile.Enable)
+	disabled := toSet(profile.Disable)
+
+	var windows []WindowConfig
+	for _, window := range c.Tmux.Windows {
+		if disabled[window.Name] {
+			continue
+		}
+		windowEnabled := len(enabled) == 0 || enabled[window.Name]
+
+		var panes []PaneConfig
+		for _, pane := range window.Panes {
+			if pane.Name != "" && disabled[pane.Name] {
+				continue
+			}
+			if !windowEnabled && (pane.Name == "" || !enabled[pane.Name]) {
+				continue
+			}
+			if len(profile.Env) > 0 {
+				pane.Env = mergeEnv(pane.Env, profile.Env)
+			}
+			panes = append(panes, pane)
+		}
+		if len(panes) == 0 {
+			continue
+		}
+		window.Panes = panes
+		windows = append(windows, window)
+	}
+
+	if len(windows) == 0 {
+		return fmt.Errorf("config: profile '%s' does not enable any panes", name)
+	}
+	c.Tmux.Windows = windows
+	c.Profile = name
 	return nil
 }
 
-// envVarRegex matches $VAR or ${VAR} patterns
-var envVarRegex = regexp.MustCompile(`\$\{([^}]+)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+func toSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
 
-// interpolateEnvVars replaces environment variable placeholders with their values
-func interpolateEnvVars(input string) string {
-	return envVarRegex.ReplaceAllStringFunc(input, func(match string) string {
-		// Extract variable name
-		var varName string
-		if strings.HasPrefix(match, "${") {
-			varName = match[2 : len(match)-1]
-		} else {
-			varName = match[1:]
+// SourceOf returns the file that set the value at key (e.g. "tmux.session"),
+// or "" when the key was not set by any file.
+func (c *Config) SourceOf(key string) string {
+	for _, src := range c.Sources {
+		if src.Key == key {
+			return src.File
 		}
+	}
+	return ""
+}
 
-		// Get environment variable value
-		value := os.Getenv(varName)
-		if value == "" {
-			// Return original if not set (could also return empty string)
-			return match
+// ResolveLogsDir returns logs_dir resolved against the config file's directory,
+// so every command agrees on the location regardless of the shell's cwd.
+func (c *Config) ResolveLogsDir() string {
+	return c.resolve(c.LogsDir)
+}
+
+// TmuxSocket returns the tmux server socket for the session: a socket name
+// (for tmux -L), a path (for tmux -S, resolved against the config directory)
+// or "" for the user's default server.
+func (c *Config) TmuxSocket() string {
+	switch socket := c.Tmux.Socket; {
+	case socket == "":
+		return SessionSocketName(c.Tmux.Session)
+	case socket == DefaultTmuxSocket:
+		return ""
+	case strings.ContainsRune(socket, '/') || strings.HasPrefix(socket, "~"):
+		return c.resolve(socket)
+	default:
+		return socket
+	}
+}
+
+// ResolveTmuxConfig returns tmux.config resolved against the config directory.
+func (c *Config) ResolveTmuxConfig() string {
+	return c.resolve(c.Tmux.Config)
+}
+
+func (c *Config) resolve(path string) string {
+	if c.Dir == "" {
+		return path
+	}
+	return resolvePath(c.Dir, path)
+}
+
+// RunFilePath returns the path of a log file inside a run directory.
+// Absolute file paths are returned unchanged.
+func RunFilePath(runDir, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(runDir, file)
+}
+
+// applyEnvFiles layers dotenv values under the explicit env maps:
+// top-level env_file < window env < pane env_file < pane env. Dotenv values
+// the process environment also sets already carry its value (readEnvFiles).
+func (c *Config) applyEnvFiles(topLevel map[string]string) error {
+	for i := range c.Tmux.Windows {
+		window := &c.Tmux.Windows[i]
+		window.Env = mergeEnv(topLevel, window.Env)
+		for j := range window.Panes {
+			pane := &window.Panes[j]
+			if len(pane.EnvFile) == 0 {
+				continue
+			}
+			fileEnv, err := readEnvFiles(c.Dir, pane.EnvFile)
+			if err != nil {
+				return fmt.Errorf("tmux.windows[%d].panes[%d].env_file: %w", i, j, err)
+			}
+			pane.Env = mergeEnv(fileEnv, pane.Env)
+		}
+	}
+	return nil
+}
+
+// validateProfiles checks that every profile only references known windows/panes.
+func (c *Config) validateProfiles() []FieldError {
+	known := make(map[string]bool)
+	for _, window := range c.Tmux.Windows {
+		known[window.Name] = true
+		for _, pane := range window.Panes {
+			if pane.Name != "" {
+				known[pane.Name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []FieldError
+	for _, name := range names {
+		profile := c.Profiles[name]
+		for _, ref := range append(append([]string{}, profile.Enable...), profile.Disable...) {
+			if !known[ref] {
+				problems = append(problems, FieldError{
+					Path:    "profiles." + name,
+					Message: fmt.Sprintf("references unknown window or pane '%s'", ref),
+				})
+			}
+		}
+		problems = append(problems, validateEnv(fmt.Sprintf("profiles.%s.env", name), profile.Env)...)
+	}
+	return problems
+}
+
+func validateRestart(path string, pane PaneConfig) []FieldError {
+	var problems []FieldError
+	switch pane.Restart {
+	case "", RestartNever, RestartOnFailure, RestartAlways:
+	default:
+		problems = append(problems, FieldError{Path: path + ".restart", Message: fmt.Sprintf("must be 'never', 'on-failure' or 'always', got '%s'", pane.Restart)})
+	}
+	if pane.MaxRetries < 0 {
+		problems = append(problems, FieldError{Path: path + ".max_retries", Message: fmt.Sprintf("must be non-negative, got %d", pane.MaxRetries)})
+	}
+	if pane.Backoff != "" {
+		if d, err := time.ParseDuration(pane.Backoff); err != nil || d <= 0 {
+			problems = append(problems, FieldError{Path: path + ".backoff", Message: fmt.Sprintf("must be a positive duration like '2s', got '%s'", pane.Backoff)})
+		}
+	}
+	return problems
+}
+
+// envNameRegex matches a valid environment variable name
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateRotation(path, maxFileSize string, maxFiles int) []FieldError {
+	var problems []FieldError
+	if _, err := ParseSize(maxFileSize); err != nil {
+		problems = append(problems, FieldError{Path: path + ".max_file_size", Message: fmt.Sprintf("must be a size like '10MB', got '%s'", maxFileSize)})
+	}
+	if maxFiles < 0 {
+		problems = append(problems, FieldError{Path: path + ".max_files", Message: fmt.Sprintf("must be non-negative, got %d", maxFiles)})
+	}
+	return problems
+}
+
+func validateEnv(field string, env map[string]string) []FieldError {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []FieldError
+	for _, name := range names {
+		if !envNameRegex.MatchString(name) {
+			problems = append(problems, FieldError{Path: field, Message: fmt.Sprintf("has invalid variable name '%s'", name)})
 		}
-		return value
-	})
+	}
+	return problems
+}
+
+// resolveCwds makes relative window and pane cwd values absolute against baseDir
+// (the directory containing devlog.yml), so panes start in the same place no
+// matter where devlog was invoked from.
+func (c *Config) resolveCwds(baseDir string) {
+	for i := range c.Tmux.Windows {
+		window := &c.Tmux.Windows[i]
+		window.Cwd = resolvePath(baseDir, window.Cwd)
+		for j := range window.Panes {
+			window.Panes[j].Cwd = resolvePath(baseDir, window.Panes[j].Cwd)
+		}
+	}
+}
+
+// resolvePath joins a relative path onto baseDir. Empty and absolute paths are
+// returned unchanged; a leading ~/ expands to the user's home directory.
+func resolvePath(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	abs, err := filepath.Abs(filepath.Join(baseDir, path))
+	if err != nil {
+		return filepath.Join(baseDir, path)
+	}
+	return abs
 }
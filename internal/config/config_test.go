@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_ValidConfig(t *testing.T) {
@@ -377,3 +378,502 @@ func TestValidate_NegativeRetentionDays(t *testing.T) {
 		t.Errorf("Validate() error = %q, want error about retention_days", err.Error())
 	}
 }
+
+func TestLoad_PaneNameCwdEnv(t *testing.T) {
+	content := `
+version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: server
+      cwd: packages
+      env:
+        NODE_ENV: development
+      panes:
+        - name: web
+          cmd: npm run dev
+          cwd: packages/web
+          env:
+            PORT: "3000"
+        - name: api
+          cmd: npm run api
+          cwd: /srv/api
+        - name: worker
+          cmd: npm run worker
+`
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	window := cfg.Tmux.Windows[0]
+	if window.Cwd != filepath.Join(tmpDir, "packages") {
+		t.Errorf("window Cwd = %q, want %q", window.Cwd, filepath.Join(tmpDir, "packages"))
+	}
+
+	web := window.Panes[0]
+	if web.Name != "web" {
+		t.Errorf("Name = %q, want %q", web.Name, "web")
+	}
+	if got := window.PaneCwd(web); got != filepath.Join(tmpDir, "packages", "web") {
+		t.Errorf("PaneCwd(web) = %q, want %q", got, filepath.Join(tmpDir, "packages", "web"))
+	}
+	env := window.PaneEnv(web)
+	if env["NODE_ENV"] != "development" || env["PORT"] != "3000" {
+		t.Errorf("PaneEnv(web) = %v, want NODE_ENV and PORT", env)
+	}
+
+	if got := window.PaneCwd(window.Panes[1]); got != "/srv/api" {
+		t.Errorf("PaneCwd(api) = %q, want absolute path unchanged", got)
+	}
+	if got := window.PaneCwd(window.Panes[2]); got != window.Cwd {
+		t.Errorf("PaneCwd(worker) = %q, want window cwd %q", got, window.Cwd)
+	}
+}
+
+func TestValidate_PaneNamesAndEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		panes   []PaneConfig
+		wantErr string
+	}{
+		{
+			name: "duplicate pane name",
+			panes: []PaneConfig{
+				{Name: "web", Cmd: "echo a"},
+				{Name: "web", Cmd: "echo b"},
+			},
+			wantErr: "name 'web' is already used",
+		},
+		{
+			name: "invalid env name",
+			panes: []PaneConfig{
+				{Cmd: "echo a", Env: map[string]string{"BAD-NAME": "x"}},
+			},
+			wantErr: "invalid variable name 'BAD-NAME'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Version: "1.0",
+				Project: "test",
+				RunMode: "timestamped",
+				Tmux: TmuxConfig{
+					Session: "test",
+					Windows: []WindowConfig{{Name: "main", Panes: tt.panes}},
+				},
+			}
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("Validate() expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %q, want containing %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ResolvesPathsAgainstConfigDir(t *testing.T) {
+	content := `
+version: "1.0"
+project: myapp
+logs_dir: ./logs
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo test
+          cwd: web
+`
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	// Run from an unrelated subdirectory to make sure the shell cwd is ignored
+	subDir := filepath.Join(tmpDir, "nested", "dir")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+	t.Chdir(subDir)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if cfg.Dir != tmpDir {
+		t.Errorf("Dir = %q, want %q", cfg.Dir, tmpDir)
+	}
+	if got := cfg.ResolveLogsDir(); got != filepath.Join(tmpDir, "logs") {
+		t.Errorf("ResolveLogsDir() = %q, want %q", got, filepath.Join(tmpDir, "logs"))
+	}
+	if got := cfg.Tmux.Windows[0].Panes[0].Cwd; got != filepath.Join(tmpDir, "web") {
+		t.Errorf("pane Cwd = %q, want %q", got, filepath.Join(tmpDir, "web"))
+	}
+}
+
+func TestRunFilePath(t *testing.T) {
+	if got := RunFilePath("/logs/run", "browser/console.log"); got != filepath.Join("/logs/run", "browser", "console.log") {
+		t.Errorf("RunFilePath(relative) = %q", got)
+	}
+	if got := RunFilePath("/logs/run", "/var/log/browser.log"); got != "/var/log/browser.log" {
+		t.Errorf("RunFilePath(absolute) = %q", got)
+	}
+}
+
+func profileTestConfig() *Config {
+	return &Config{
+		Version: "1.0",
+		Project: "test",
+		RunMode: "timestamped",
+		Tmux: TmuxConfig{
+			Session: "test",
+			Windows: []WindowConfig{
+				{
+					Name: "frontend",
+					Panes: []PaneConfig{
+						{Name: "web", Cmd: "npm run web", Env: map[string]string{"PORT": "3000"}},
+						{Name: "storybook", Cmd: "npm run storybook"},
+					},
+				},
+				{
+					Name: "backend",
+					Panes: []PaneConfig{
+						{Name: "api", Cmd: "npm run api"},
+						{Name: "worker", Cmd: "npm run worker"},
+					},
+				},
+			},
+		},
+		Profiles: map[string]ProfileConfig{
+			"frontend": {Enable: []string{"frontend"}, Disable: []string{"storybook"}},
+			"api":      {Enable: []string{"api"}, Env: map[string]string{"PORT": "4000"}},
+			"nothing":  {Disable: []string{"frontend", "backend"}},
+		},
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		profile   string
+		wantPanes []string
+	}{
+		{profile: "", wantPanes: []string{"web", "storybook", "api", "worker"}},
+		{profile: "frontend", wantPanes: []string{"web"}},
+		{profile: "api", wantPanes: []string{"api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			cfg := profileTestConfig()
+			if err := cfg.ApplyProfile(tt.profile); err != nil {
+				t.Fatalf("ApplyProfile() failed: %v", err)
+			}
+			var got []string
+			for _, w := range cfg.Tmux.Windows {
+				for _, p := range w.Panes {
+					got = append(got, p.Name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantPanes, ",") {
+				t.Errorf("panes = %v, want %v", got, tt.wantPanes)
+			}
+			if cfg.Profile != tt.profile {
+				t.Errorf("Profile = %q, want %q", cfg.Profile, tt.profile)
+			}
+		})
+	}
+}
+
+func TestApplyProfile_EnvOverride(t *testing.T) {
+	cfg := profileTestConfig()
+	cfg.Profiles["web"] = ProfileConfig{Enable: []string{"web"}, Env: map[string]string{"PORT": "5000"}}
+	if err := cfg.ApplyProfile("web"); err != nil {
+		t.Fatalf("ApplyProfile() failed: %v", err)
+	}
+	window := cfg.Tmux.Windows[0]
+	if got := window.PaneEnv(window.Panes[0])["PORT"]; got != "5000" {
+		t.Errorf("PORT = %q, want profile override 5000", got)
+	}
+}
+
+func TestApplyProfile_Errors(t *testing.T) {
+	cfg := profileTestConfig()
+	if err := cfg.ApplyProfile("missing"); err == nil || !strings.Contains(err.Error(), "not defined") {
+		t.Errorf("ApplyProfile(missing) error = %v, want not defined", err)
+	}
+	if err := cfg.ApplyProfile("nothing"); err == nil || !strings.Contains(err.Error(), "does not enable any panes") {
+		t.Errorf("ApplyProfile(nothing) error = %v, want no panes", err)
+	}
+}
+
+func TestValidate_ProfileUnknownReference(t *testing.T) {
+	cfg := profileTestConfig()
+	cfg.Profiles["typo"] = ProfileConfig{Enable: []string{"wbe"}}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected error for unknown profile reference, got nil")
+	}
+	if !strings.Contains(err.Error(), "profiles.typo references unknown window or pane 'wbe'") {
+		t.Errorf("Validate() error = %q", err.Error())
+	}
+}
+
+func TestValidate_RestartPolicy(t *testing.T) {
+	tests := []struct {
+		pane PaneConfig
+		want string
+	}{
+		{PaneConfig{Cmd: "run", Restart: "sometimes"}, "config: tmux.windows[0].panes[0].restart must be 'never', 'on-failure' or 'always', got 'sometimes'"},
+		{PaneConfig{Cmd: "run", Restart: RestartAlways, MaxRetries: -1}, "config: tmux.windows[0].panes[0].max_retries must be non-negative, got -1"},
+		{PaneConfig{Cmd: "run", Restart: RestartOnFailure, Backoff: "soon"}, "config: tmux.windows[0].panes[0].backoff must be a positive duration like '2s', got 'soon'"},
+	}
+	for _, tt := range tests {
+		cfg := readyTestConfig(tt.pane)
+		err := cfg.Validate()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+
+	pane := PaneConfig{Cmd: "run", Restart: RestartOnFailure, MaxRetries: 5, Backoff: "2s"}
+	if err := readyTestConfig(pane).Validate(); err != nil {
+		t.Errorf("Validate() failed for valid restart policy: %v", err)
+	}
+	if !pane.Supervised() || pane.BackoffDuration() != 2*time.Second {
+		t.Errorf("Supervised() = %v, BackoffDuration() = %s", pane.Supervised(), pane.BackoffDuration())
+	}
+	if (PaneConfig{Restart: RestartNever}).Supervised() {
+		t.Error("Supervised() = true for restart: never")
+	}
+}
+
+func TestValidate_LayoutAndSplit(t *testing.T) {
+	cfg := readyTestConfig(PaneConfig{Cmd: "run"}, PaneConfig{Cmd: "run", Split: "v", Size: 30})
+	for _, layout := range []string{"", "tiled", "main-vertical", "b25d,158x40,0,0{79x40,0,0,1,78x40,80,0,2}"} {
+		cfg.Tmux.Windows[0].Layout = layout
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with layout %q failed: %v", layout, err)
+		}
+	}
+
+	tests := []struct {
+		mutate func(c *Config)
+		want   string
+	}{
+		{func(c *Config) { c.Tmux.Windows[0].Layout = "grid" }, "config: tmux.windows[0].layout must be one of tiled, even-horizontal, even-vertical, main-horizontal, main-vertical or a tmux layout string, got 'grid'"},
+		{func(c *Config) { c.Tmux.Windows[0].Panes[1].Split = "x" }, "config: tmux.windows[0].panes[1].split must be 'h' or 'v', got 'x'"},
+		{func(c *Config) { c.Tmux.Windows[0].Panes[1].Size = 100 }, "config: tmux.windows[0].panes[1].size must be a percentage between 1 and 99, got 100"},
+	}
+	for _, tt := range tests {
+		cfg := readyTestConfig(PaneConfig{Cmd: "run"}, PaneConfig{Cmd: "run"})
+		tt.mutate(cfg)
+		if err := cfg.Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestConfig_TmuxSocket(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		session, socket, want string
+	}{
+		{"myapp", "", "devlog-myapp"},
+		{"my app:1", "", "devlog-my-app-1"},
+		{"myapp", "default", ""},
+		{"myapp", "shared", "shared"},
+		{"myapp", "./tmp/tmux.sock", filepath.Join(dir, "tmp", "tmux.sock")},
+		{"myapp", "/run/devlog.sock", "/run/devlog.sock"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Dir: dir, Tmux: TmuxConfig{Session: tt.session, Socket: tt.socket}}
+		if got := cfg.TmuxSocket(); got != tt.want {
+			t.Errorf("TmuxSocket() with session %q, socket %q = %q, want %q", tt.session, tt.socket, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_TmuxConfigMissing(t *testing.T) {
+	cfg := readyTestConfig(PaneConfig{Cmd: "run"})
+	cfg.Dir = t.TempDir()
+	cfg.Tmux.Config = "devlog.tmux.conf"
+	want := "config: tmux.config file 'devlog.tmux.conf' does not exist"
+	if err := cfg.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+}
+
+func TestValidate_Shutdown(t *testing.T) {
+	tests := []struct {
+		shutdown ShutdownConfig
+		want     string
+	}{
+		{ShutdownConfig{Signals: StringList{"INT", "STOP"}}, "config: shutdown.signals[1] must be one of INT, TERM, KILL, HUP, QUIT, USR1, USR2, got 'STOP'"},
+		{ShutdownConfig{Timeout: "later"}, "config: shutdown.timeout must be a positive duration like '5s', got 'later'"},
+	}
+	for _, tt := range tests {
+		cfg := readyTestConfig(PaneConfig{Cmd: "run"})
+		cfg.Shutdown = tt.shutdown
+		if err := cfg.Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+
+	shutdown := ShutdownConfig{Signals: StringList{"sigterm", "KILL"}, Timeout: "10s"}
+	if got := shutdown.SignalNames(); strings.Join(got, " ") != "TERM KILL" {
+		t.Errorf("SignalNames() = %v, want [TERM KILL]", got)
+	}
+	if shutdown.TimeoutDuration() != 10*time.Second {
+		t.Errorf("TimeoutDuration() = %s, want 10s", shutdown.TimeoutDuration())
+	}
+	if got := (ShutdownConfig{}).SignalNames(); strings.Join(got, " ") != "INT TERM KILL" {
+		t.Errorf("default SignalNames() = %v", got)
+	}
+}
+
+func TestValidate_Redact(t *testing.T) {
+	tests := []struct {
+		redact RedactConfig
+		want   string
+	}{
+		{RedactConfig{Presets: StringList{"email", "ssh"}}, "config: redact.presets[1] must be one of bearer, jwt, aws, url-credentials, email, got 'ssh'"},
+		{RedactConfig{Patterns: StringList{"token=(\\w+"}}, "config: redact.patterns[0] must be a valid regular expression: error parsing regexp: missing closing ): `token=(\\w+`"},
+	}
+	for _, tt := range tests {
+		cfg := readyTestConfig(PaneConfig{Cmd: "run"})
+		cfg.Redact = tt.redact
+		if err := cfg.Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+
+	cfg := readyTestConfig(PaneConfig{Cmd: "run"})
+	cfg.Redact = RedactConfig{Presets: StringList{"Bearer", "url-credentials"}, Patterns: StringList{`password=(\S+)`}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"", 0},
+		{"4096", 4096},
+		{"512KB", 512 << 10},
+		{"10mb", 10 << 20},
+		{"1 G", 1 << 30},
+		{"100B", 100},
+	}
+	for _, tt := range tests {
+		if got, err := ParseSize(tt.in); err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"10TB", "-1MB", "MB", "0"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q): want error", in)
+		}
+	}
+}
+
+func TestValidate_Rotation(t *testing.T) {
+	cfg := readyTestConfig(PaneConfig{Cmd: "run", Log: "api.log", MaxFileSize: "lots"})
+	want := "config: tmux.windows[0].panes[0].max_file_size must be a size like '10MB', got 'lots'"
+	if err := cfg.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+
+	cfg = readyTestConfig(PaneConfig{Cmd: "run"})
+	cfg.Browser.MaxFiles = -1
+	want = "config: browser.max_files must be non-negative, got -1"
+	if err := cfg.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+
+	pane := PaneConfig{Log: "api.log", MaxFileSize: "1MB", MaxFiles: 3, Compress: true}
+	if !pane.Piped() {
+		t.Error("Piped() = false for max_file_size")
+	}
+	if got := pane.LogRotation(); got.MaxSize != 1<<20 || got.MaxFiles != 3 || !got.Compress {
+		t.Errorf("LogRotation() = %+v", got)
+	}
+}
+
+func TestValidate_BrowserFormat(t *testing.T) {
+	tests := []struct {
+		browser BrowserConfig
+		want    string
+	}{
+		{BrowserConfig{Format: "xml"}, "config: browser.format must be one of text, jsonl, logfmt, got 'xml'"},
+		{BrowserConfig{Format: "jsonl", Template: "{{.Message}}"}, "config: browser.template requires format 'text', got 'jsonl'"},
+		{BrowserConfig{Template: "{{.Message"}, "config: browser.template must be a valid template: template: browser:1: unclosed action"},
+	}
+	for _, tt := range tests {
+		cfg := readyTestConfig(PaneConfig{Cmd: "run"})
+		cfg.Browser = tt.browser
+		if err := cfg.Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+
+	cfg := readyTestConfig(PaneConfig{Cmd: "run"})
+	cfg.Browser = BrowserConfig{Format: "text", Template: "{{upper .Level}} {{.Message}}"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidate_Timestamps(t *testing.T) {
+	cfg := readyTestConfig(PaneConfig{Cmd: "run", Log: "api.log", Timestamps: "unix"})
+	want := "config: tmux.windows[0].panes[0].timestamps must be 'none', 'rfc3339' or 'relative', got 'unix'"
+	if err := cfg.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+
+	if (PaneConfig{Log: "api.log", Timestamps: TimestampsNone}).Piped() {
+		t.Error("Piped() = true for timestamps: none")
+	}
+	if !(PaneConfig{Log: "api.log", Timestamps: TimestampsRFC3339}).Piped() {
+		t.Error("Piped() = false for timestamps: rfc3339")
+	}
+}
+
+func TestValidate_RawLog(t *testing.T) {
+	tests := []struct {
+		pane PaneConfig
+		want string
+	}{
+		{PaneConfig{Cmd: "run", RawLog: "api.raw.log"}, "config: tmux.windows[0].panes[0].raw_log requires log"},
+		{PaneConfig{Cmd: "run", Log: "api.log", RawLog: "api.log"}, "config: tmux.windows[0].panes[0].raw_log must differ from log"},
+	}
+	for _, tt := range tests {
+		if err := readyTestConfig(tt.pane).Validate(); err == nil || err.Error() != tt.want {
+			t.Errorf("Validate() error = %v, want %q", err, tt.want)
+		}
+	}
+
+	if !(PaneConfig{Log: "api.log", Sanitize: true}).Piped() {
+		t.Error("Piped() = false for sanitize: true")
+	}
+	if (PaneConfig{Sanitize: true}).Piped() {
+		t.Error("Piped() = true without log")
+	}
+}
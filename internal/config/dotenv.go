@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StringList is a list of strings that may also be written as a single scalar
+// in YAML (e.g. `env_file: .env` or `env_file: [.env, .env.local]`).
+type StringList []string
+
+// UnmarshalYAML accepts either a scalar or a sequence of scalars.
+func (s *StringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Value == "" {
+			*s = nil
+			return nil
+		}
+		*s = StringList{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// readEnvFiles loads dotenv files in order (later files win), resolving
+// relative paths against baseDir. Variables the process environment also
+// sets take the process environment's value, as they do for interpolation.
+func readEnvFiles(baseDir string, files []string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, file := range files {
+		path := resolvePath(baseDir, file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: failed to read env_file: %w", err)
+		}
+		values, err := parseDotenv(string(data), env)
+		if err != nil {
+			var se *sourceError
+			line := 0
+			if errors.As(err, &se) {
+				line = se.Line
+			}
+			return nil, &sourceError{File: path, Line: line, Err: fmt.Errorf("config: %s: %w", path, err)}
+		}
+		for k, v := range values {
+			env[k] = v
+		}
+	}
+	for k := range env {
+		if v, ok := os.LookupEnv(k); ok {
+			env[k] = v
+		}
+	}
+	return env, nil
+}
+
+// parseDotenv parses dotenv-style KEY=value lines. It supports blank lines,
+// # comments, an optional `export ` prefix, single quotes (literal), double
+// quotes (with \n, \t, \", \\, \$ and $ references) and unquoted values (with
+// trailing " #" comments and $ references). References resolve against the
+// process environment, then keys defined earlier in the file, then prior.
+// A $ that does not start a reference is kept as written.
+func parseDotenv(data string, prior map[string]string) (map[string]string, error) {
+	values := make(map[string]string)
+	lookup := func(name string) (string, bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true
+		}
+		if v, ok := values[name]; ok {
+			return v, true
+		}
+		v, ok := prior[name]
+		return v, ok
+	}
+
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, rest, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, lineError(lineNo, "expected KEY=value")
+		}
+		name = strings.TrimSpace(name)
+		if !envNameRegex.MatchString(name) {
+			return nil, lineError(lineNo, "invalid variable name '%s'", name)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, "'"):
+			// Single-quoted values are literal and may span lines
+			body, consumed, err := quotedValue(rest[1:], lines[i+1:], '\'')
+			if err != nil {
+				return nil, lineError(lineNo, "%w", err)
+			}
+			i += consumed
+			value = body
+		case strings.HasPrefix(rest, `"`):
+			body, consumed, err := quotedValue(rest[1:], lines[i+1:], '"')
+			if err != nil {
+				return nil, lineError(lineNo, "%w", err)
+			}
+			i += consumed
+			expanded, err := expandDotenvValue(body, lookup, true)
+			if err != nil {
+				return nil, lineError(lineNo, "%w", err)
+			}
+			value = expanded
+		default:
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				rest = rest[:idx]
+			}
+			expanded, err := expandDotenvValue(strings.TrimSpace(rest), lookup, false)
+			if err != nil {
+				return nil, lineError(lineNo, "%w", err)
+			}
+			value = expanded
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// lineError is a parse error on a line of a dotenv file.
+func lineError(line int, format string, args ...any) error {
+	return &sourceError{Line: line, Err: fmt.Errorf("line %d: "+format, append([]any{line}, args...)...)}
+}
+
+// quotedValue returns the text up to the closing quote, continuing onto the
+// following lines when needed. consumed is the number of extra lines used.
+func quotedValue(first string, more []string, quote byte) (string, int, error) {
+	text := first
+	for consumed := 0; ; consumed++ {
+		if end := closingQuote(text, quote); end >= 0 {
+			return text[:end], consumed, nil
+		}
+		if consumed >= len(more) {
+			return "", 0, fmt.Errorf("unterminated %c-quoted value", quote)
+		}
+		text += "\n" + more[consumed]
+	}
+}
+
+// closingQuote finds the unescaped closing quote in s, or -1.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// expandDotenvValue expands $VAR and ${...} references in a dotenv value and,
+// when doubleQuoted, its backslash escapes. Unlike config interpolation, $$ is
+// not an escape: only \$ inside double quotes keeps a reference literal.
+func expandDotenvValue(s string, lookup envLookup, doubleQuoted bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case doubleQuoted && c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := matchingBrace(s, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference '%s'", s[i:])
+			}
+			value, err := expandBraced(s[i+2:end], s[i:end+1], lookup, false)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case c == '$' && i+1 < len(s) && isEnvNameStart(s[i+1]):
+			j := i + 1
+			for j < len(s) && isEnvNameChar(s[j]) {
+				j++
+			}
+			if value, ok := lookup(s[i+1 : j]); ok {
+				b.WriteString(value)
+			} else {
+				b.WriteString(s[i:j])
+			}
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// mergeEnv returns base overlaid with overlay. Returns nil when both are empty.
+func mergeEnv(base, overlay map[string]string) map[string]string {
+	if len(base) == 0 && len(overlay) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		env[k] = v
+	}
+	for k, v := range overlay {
+		env[k] = v
+	}
+	return env
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	data := `# comment
+PORT=3000
+export HOST=localhost
+URL=http://${HOST}:${PORT} # trailing comment
+SINGLE='literal $PORT # not a comment'
+DOUBLE="line1\nline2 \"quoted\" \$PORT"
+EMPTY=
+SPACED = value with spaces
+MULTI="first
+second"
+`
+	values, err := parseDotenv(data, nil)
+	if err != nil {
+		t.Fatalf("parseDotenv() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"PORT":   "3000",
+		"HOST":   "localhost",
+		"URL":    "http://localhost:3000",
+		"SINGLE": "literal $PORT # not a comment",
+		"DOUBLE": "line1\nline2 \"quoted\" $PORT",
+		"EMPTY":  "",
+		"SPACED": "value with spaces",
+		"MULTI":  "first\nsecond",
+	}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+	if len(values) != len(want) {
+		t.Errorf("len(values) = %d, want %d: %v", len(values), len(want), values)
+	}
+}
+
+func TestParseDotenv_DollarSigns(t *testing.T) {
+	values, err := parseDotenv("PRICE=$$5 and $\nPASS=a$$b\nQUOTED=\"\\$HOME\"\n", nil)
+	if err != nil {
+		t.Fatalf("parseDotenv() failed: %v", err)
+	}
+	want := map[string]string{"PRICE": "$$5 and $", "PASS": "a$$b", "QUOTED": "$HOME"}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr string
+	}{
+		{data: "NOVALUE\n", wantErr: "line 1: expected KEY=value"},
+		{data: "OK=1\nBAD-NAME=x\n", wantErr: "line 2: invalid variable name 'BAD-NAME'"},
+		{data: "Q=\"open\n", wantErr: "unterminated"},
+	}
+	for _, tt := range tests {
+		_, err := parseDotenv(tt.data, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseDotenv(%q) error = %v, want containing %q", tt.data, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoad_EnvFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, ".env"), "DEVLOG_TEST_WEB_PORT=5173\nSHARED=top\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "api", ".env"), "API_PORT=4000\nSHARED=api\n")
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `
+version: "1.0"
+project: myapp
+env_file: .env
+tmux:
+  session: dev
+  windows:
+    - name: main
+      env:
+        WINDOW: "1"
+      panes:
+        - name: web
+          cmd: vite --port $DEVLOG_TEST_WEB_PORT
+        - name: api
+          cmd: npm run api
+          env_file: [api/.env]
+          env:
+            API_PORT: "4001"
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	window := cfg.Tmux.Windows[0]
+	if got := window.Panes[0].Cmd; got != "vite --port 5173" {
+		t.Errorf("Cmd = %q, want interpolated from .env", got)
+	}
+
+	webEnv := window.PaneEnv(window.Panes[0])
+	if webEnv["DEVLOG_TEST_WEB_PORT"] != "5173" || webEnv["SHARED"] != "top" || webEnv["WINDOW"] != "1" {
+		t.Errorf("web env = %v", webEnv)
+	}
+
+	apiEnv := window.PaneEnv(window.Panes[1])
+	if apiEnv["SHARED"] != "api" {
+		t.Errorf("api SHARED = %q, want pane env_file to win over top-level", apiEnv["SHARED"])
+	}
+	if apiEnv["API_PORT"] != "4001" {
+		t.Errorf("api API_PORT = %q, want explicit env to win over env_file", apiEnv["API_PORT"])
+	}
+}
+
+func TestLoad_EnvFilePrecedence(t *testing.T) {
+	t.Setenv("DEVLOG_TEST_SHELL_WINS", "shell")
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, ".env"), "DEVLOG_TEST_SHELL_WINS=top\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "api", ".env"), "DEVLOG_TEST_SHELL_WINS=api\nDEVLOG_TEST_API_PORT=4000\n")
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `
+version: "1.0"
+project: myapp
+env_file: .env
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - name: web
+          cmd: echo $DEVLOG_TEST_SHELL_WINS $DEVLOG_TEST_API_PORT
+        - name: api
+          cmd: serve --port $DEVLOG_TEST_API_PORT --mode $DEVLOG_TEST_SHELL_WINS
+          env_file: api/.env
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	window := cfg.Tmux.Windows[0]
+	if got := window.Panes[0].Cmd; got != "echo shell $DEVLOG_TEST_API_PORT" {
+		t.Errorf("web Cmd = %q, want shell value and the api env_file out of scope", got)
+	}
+	if got := window.Panes[1].Cmd; got != "serve --port 4000 --mode shell" {
+		t.Errorf("api Cmd = %q, want pane env_file interpolated under the shell env", got)
+	}
+	for _, pane := range window.Panes {
+		if got := window.PaneEnv(pane)["DEVLOG_TEST_SHELL_WINS"]; got != "shell" {
+			t.Errorf("%s env DEVLOG_TEST_SHELL_WINS = %q, want the process env to win over env_file", pane.Name, got)
+		}
+	}
+}
+
+func TestLoad_EnvFileMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	content := `
+version: "1.0"
+project: myapp
+env_file: missing.env
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo test
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	_, err := Load(configPath)
+	if err == nil || !strings.Contains(err.Error(), "failed to read env_file") {
+		t.Errorf("Load() error = %v, want env_file read error", err)
+	}
+}
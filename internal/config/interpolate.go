@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rawTag marks a YAML scalar that must be kept verbatim (no interpolation),
+// e.g. `cmd: !raw echo $HOME` so the pane's shell expands $HOME instead.
+const rawTag = "!raw"
+
+// envLookup resolves a variable name to its value.
+type envLookup func(name string) (string, bool)
+
+// interpolateNode expands environment variables in every scalar value under n.
+// Mapping keys and scalars tagged !raw are left untouched. Mappings with a
+// registered scope (panes with an env_file) use that scope's lookup.
+func (l *layerLoader) interpolateNode(n *yaml.Node, key string, lookup envLookup, strict bool) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		if scoped, ok := l.scopes[n]; ok {
+			lookup = scoped
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			childKey := n.Content[i].Value
+			if key != "" {
+				childKey = key + "." + childKey
+			}
+			if err := l.interpolateNode(n.Content[i+1], childKey, lookup, strict); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			if err := l.interpolateNode(item, fmt.Sprintf("%s[%d]", key, i), lookup, strict); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if n.Tag == rawTag {
+			n.Tag = "!!str"
+			return nil
+		}
+		value, err := interpolateEnvVars(n.Value, lookup, strict)
+		if err != nil {
+			return fmt.Errorf("config: %s: %s: %w", l.origins[n], key, err)
+		}
+		if value != n.Value {
+			n.Value = value
+			// Keep interpolated values typed as in the source: a quoted
+			// "$PORT" stays a string, an unquoted $MAX_RUNS may become an int.
+			if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) == 0 {
+				n.Tag = ""
+			}
+		}
+	}
+	return nil
+}
+
+// interpolateEnvVars expands shell-style variable references in input:
+//
+//	$VAR, ${VAR}        value of VAR
+//	${VAR:-default}     default when VAR is unset or empty
+//	${VAR-default}      default when VAR is unset
+//	${VAR:?message}     error when VAR is unset or empty
+//	${VAR?message}      error when VAR is unset
+//	$$                  a literal $
+//
+// Unset variables are left as written unless strict is true, in which case
+// they are reported as errors.
+func interpolateEnvVars(input string, lookup envLookup, strict bool) (string, error) {
+	var b strings.Builder
+	b.Grow(len(input))
+
+	for i := 0; i < len(input); {
+		if input[i] != '$' || i+1 >= len(input) {
+			b.WriteByte(input[i])
+			i++
+			continue
+		}
+
+		next := input[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i += 2
+		case next == '{':
+			end := matchingBrace(input, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable reference '%s'", input[i:])
+			}
+			value, err := expandBraced(input[i+2:end], input[i:end+1], lookup, strict)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end + 1
+		case isEnvNameStart(next):
+			j := i + 1
+			for j < len(input) && isEnvNameChar(input[j]) {
+				j++
+			}
+			name := input[i+1 : j]
+			if value, ok := lookup(name); ok {
+				b.WriteString(value)
+			} else if strict {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			} else {
+				b.WriteString(input[i:j])
+			}
+			i = j
+		default:
+			b.WriteByte('$')
+			i++
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandBraced evaluates the body of a ${...} reference. original is the full
+// reference text, used when an unset variable is passed through.
+func expandBraced(expr, original string, lookup envLookup, strict bool) (string, error) {
+	n := 0
+	for n < len(expr) && isEnvNameChar(expr[n]) {
+		n++
+	}
+	name, op := expr[:n], expr[n:]
+	if name == "" || !isEnvNameStart(name[0]) {
+		return "", fmt.Errorf("invalid variable reference '%s'", original)
+	}
+
+	value, set := lookup(name)
+
+	colon := strings.HasPrefix(op, ":")
+	missing := !set || (colon && value == "")
+	if colon {
+		op = op[1:]
+	}
+
+	switch {
+	case op == "" && !colon:
+		if set {
+			return value, nil
+		}
+		if strict {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return original, nil
+	case strings.HasPrefix(op, "-"):
+		if missing {
+			return interpolateEnvVars(op[1:], lookup, strict)
+		}
+		return value, nil
+	case strings.HasPrefix(op, "?"):
+		if missing {
+			message := strings.TrimSpace(op[1:])
+			if message == "" {
+				message = "is required"
+			}
+			return "", fmt.Errorf("%s: %s", name, message)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid variable reference '%s'", original)
+	}
+}
+
+// matchingBrace returns the index of the } closing a ${ whose body starts at
+// start, allowing nested ${...} in defaults. Returns -1 when unterminated.
+func matchingBrace(input string, start int) int {
+	depth := 1
+	for k := start; k < len(input); k++ {
+		switch input[k] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return k
+			}
+		}
+	}
+	return -1
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || (c >= '0' && c <= '9')
+}
+
+// osEnvLookup resolves variables from the process environment.
+func osEnvLookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
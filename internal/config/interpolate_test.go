@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mapLookup(env map[string]string) envLookup {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestInterpolateEnvVars(t *testing.T) {
+	env := map[string]string{"PORT": "3000", "EMPTY": "", "HOST": "localhost"}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "--port $PORT", want: "--port 3000"},
+		{input: "${HOST}:${PORT}", want: "localhost:3000"},
+		{input: "${MISSING:-8080}", want: "8080"},
+		{input: "${EMPTY:-fallback}", want: "fallback"},
+		{input: "${EMPTY-fallback}", want: ""},
+		{input: "${MISSING-fallback}", want: "fallback"},
+		{input: "${MISSING:-${PORT}}", want: "3000"},
+		{input: "${PORT:?port required}", want: "3000"},
+		{input: "cost: $$5", want: "cost: $5"},
+		{input: "$$HOME", want: "$HOME"},
+		{input: "$MISSING and ${MISSING}", want: "$MISSING and ${MISSING}"},
+		{input: "100$ and $1", want: "100$ and $1"},
+	}
+
+	for _, tt := range tests {
+		got, err := interpolateEnvVars(tt.input, mapLookup(env), false)
+		if err != nil {
+			t.Errorf("interpolateEnvVars(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("interpolateEnvVars(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolateEnvVars_Errors(t *testing.T) {
+	env := map[string]string{"EMPTY": ""}
+
+	tests := []struct {
+		input   string
+		strict  bool
+		wantErr string
+	}{
+		{input: "${DB_URL:?set DB_URL in .env}", wantErr: "DB_URL: set DB_URL in .env"},
+		{input: "${EMPTY:?}", wantErr: "EMPTY: is required"},
+		{input: "$MISSING", strict: true, wantErr: "MISSING is not set"},
+		{input: "${MISSING}", strict: true, wantErr: "MISSING is not set"},
+		{input: "${MISSING", wantErr: "unterminated"},
+		{input: "${1BAD}", wantErr: "invalid variable reference"},
+		{input: "${NAME:x}", wantErr: "invalid variable reference"},
+	}
+
+	for _, tt := range tests {
+		_, err := interpolateEnvVars(tt.input, mapLookup(env), tt.strict)
+		if err == nil {
+			t.Errorf("interpolateEnvVars(%q) expected error containing %q, got nil", tt.input, tt.wantErr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("interpolateEnvVars(%q) error = %q, want containing %q", tt.input, err.Error(), tt.wantErr)
+		}
+	}
+}
+
+func TestLoad_InterpolationRawStrictAndRequired(t *testing.T) {
+	t.Setenv("DEVLOG_TEST_MAX_RUNS", "7")
+
+	content := `
+version: "1.0"
+project: myapp
+max_runs: $DEVLOG_TEST_MAX_RUNS
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: !raw echo $HOME $$
+        - cmd: echo ${DEVLOG_TEST_UNSET:-default}
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.MaxRuns != 7 {
+		t.Errorf("MaxRuns = %d, want 7", cfg.MaxRuns)
+	}
+	if got := cfg.Tmux.Windows[0].Panes[0].Cmd; got != "echo $HOME $$" {
+		t.Errorf("raw Cmd = %q, want verbatim", got)
+	}
+	if got := cfg.Tmux.Windows[0].Panes[1].Cmd; got != "echo default" {
+		t.Errorf("Cmd = %q, want default applied", got)
+	}
+
+	// Required variable fails at load time with file and key context
+	required := strings.Replace(content, "echo ${DEVLOG_TEST_UNSET:-default}", "echo ${DEVLOG_TEST_UNSET:?needed for api}", 1)
+	if err := os.WriteFile(configPath, []byte(required), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	_, err = Load(configPath)
+	if err == nil {
+		t.Fatal("Load() expected error for required variable, got nil")
+	}
+	for _, want := range []string{configPath, "tmux.windows[0].panes[1].cmd", "DEVLOG_TEST_UNSET: needed for api"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Load() error = %q, want containing %q", err.Error(), want)
+		}
+	}
+
+	// Strict mode rejects any unset variable
+	strict := "strict_env: true\n" + strings.Replace(content, "${DEVLOG_TEST_UNSET:-default}", "$DEVLOG_TEST_UNSET", 1)
+	if err := os.WriteFile(configPath, []byte(strict), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	_, err = Load(configPath)
+	if err == nil || !strings.Contains(err.Error(), "DEVLOG_TEST_UNSET is not set") {
+		t.Errorf("Load() error = %v, want strict unset error", err)
+	}
+}
+
+func TestLoad_StrictEnvBooleans(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantStrict bool
+		wantErr    string
+	}{
+		{value: "true", wantStrict: true},
+		{value: "True", wantStrict: true},
+		{value: "yes", wantStrict: true},
+		{value: "!!bool true", wantStrict: true},
+		{value: "false"},
+		{value: "sometimes", wantErr: "strict_env"},
+	}
+
+	for _, tt := range tests {
+		content := "strict_env: " + tt.value + `
+version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo $DEVLOG_TEST_UNSET
+`
+		configPath := filepath.Join(t.TempDir(), "devlog.yml")
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write test config: %v", err)
+		}
+
+		cfg, err := Load(configPath)
+		switch {
+		case tt.wantErr != "":
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("strict_env: %s: Load() error = %v, want containing %q", tt.value, err, tt.wantErr)
+			}
+		case tt.wantStrict:
+			if err == nil || !strings.Contains(err.Error(), "DEVLOG_TEST_UNSET is not set") {
+				t.Errorf("strict_env: %s: Load() error = %v, want strict unset error", tt.value, err)
+			}
+		case err != nil:
+			t.Errorf("strict_env: %s: Load() failed: %v", tt.value, err)
+		case cfg.StrictEnv:
+			t.Errorf("strict_env: %s: StrictEnv = true, want false", tt.value)
+		}
+	}
+}
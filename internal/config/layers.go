@@ -0,0 +1,424 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extendsKey is the top-level key that pulls in one or more base config files.
+const extendsKey = "extends"
+
+// ValueSource records which file a config value came from.
+type ValueSource struct {
+	Key   string // dotted path, e.g. "tmux.windows[0].panes[1].cmd"
+	Value string
+	File  string
+}
+
+// LocalOverridePath returns the per-developer override file for a config path,
+// e.g. devlog.yml -> devlog.local.yml.
+func LocalOverridePath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".local" + ext
+}
+
+// layerLoader merges a config file with its extends chain and tracks the
+// origin file of every leaf node.
+type layerLoader struct {
+	origins map[*yaml.Node]string
+	files   []string
+	loading map[string]bool
+	dotenv  map[string]string        // values from the top-level env_file list
+	rootDir string                   // absolute directory of the main config file
+	scopes  map[*yaml.Node]envLookup // pane mappings interpolated with their own env_file values
+
+	migratedFrom string // schema version before in-memory migration
+}
+
+func newLayerLoader() *layerLoader {
+	return &layerLoader{
+		origins: make(map[*yaml.Node]string),
+		loading: make(map[string]bool),
+		scopes:  make(map[*yaml.Node]envLookup),
+	}
+}
+
+// loadLayers reads path, its extends: bases and (when present) the local
+// override file, and returns the merged YAML mapping.
+//
+// Merge rules:
+//   - mappings are merged key by key, recursively
+//   - lists whose items are all mappings with a name (windows, named panes)
+//     are merged by name; unmatched overlay items are appended
+//   - any other value, including other lists and an explicit empty list, is
+//     replaced by the overlay
+//
+// Relative cwd, env_file and tmux.config paths in files from another
+// directory (extends: bases) are made absolute against that file's directory.
+func (l *layerLoader) loadLayers(path string) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	l.rootDir = filepath.Dir(absPath)
+
+	merged, err := l.loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	localPath := LocalOverridePath(path)
+	if _, err := os.Stat(localPath); err == nil {
+		local, err := l.loadFile(localPath)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, local)
+	}
+
+	// Upgrade older schema versions in memory; `devlog migrate` rewrites the file
+	from, _, err := migrateNode(merged, false)
+	if err != nil {
+		return nil, err
+	}
+	if from != "" && from != CurrentVersion {
+		l.migratedFrom = from
+	}
+
+	// Load top-level env_file values; the process environment takes precedence
+	// (see readEnvFiles), matching how a shell would see `source .env`.
+	var envFiles StringList
+	if idx := mappingIndex(merged, "env_file"); idx >= 0 {
+		if err := merged.Content[idx+1].Decode(&envFiles); err != nil {
+			return nil, fmt.Errorf("config: env_file: %w", err)
+		}
+	}
+	for i, file := range envFiles {
+		expanded, err := interpolateEnvVars(file, osEnvLookup, true)
+		if err != nil {
+			return nil, fmt.Errorf("config: env_file: %w", err)
+		}
+		envFiles[i] = expanded
+	}
+	l.dotenv, err = readEnvFiles(l.rootDir, envFiles)
+	if err != nil {
+		return nil, err
+	}
+	lookup := func(name string) (string, bool) {
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true
+		}
+		v, ok := l.dotenv[name]
+		return v, ok
+	}
+
+	// Interpolate after merging so strict_env applies to every layer
+	var strict bool
+	if idx := mappingIndex(merged, "strict_env"); idx >= 0 {
+		if err := merged.Content[idx+1].Decode(&strict); err != nil {
+			return nil, fmt.Errorf("config: strict_env: %w", err)
+		}
+	}
+	if err := l.scopePaneEnvFiles(merged, lookup, strict); err != nil {
+		return nil, err
+	}
+	if err := l.interpolateNode(merged, "", lookup, strict); err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// scopePaneEnvFiles reads the env_file list of every pane and registers a
+// lookup for the pane's mapping that sees those values over the top-level
+// ones, so `${VAR}` in a pane's settings can use its own env_file.
+func (l *layerLoader) scopePaneEnvFiles(root *yaml.Node, lookup envLookup, strict bool) error {
+	windows := mappingChild(mappingChild(root, "tmux"), "windows")
+	if windows == nil || windows.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for i, window := range windows.Content {
+		panes := mappingChild(window, "panes")
+		if panes == nil || panes.Kind != yaml.SequenceNode {
+			continue
+		}
+		for j, pane := range panes.Content {
+			node := mappingChild(pane, "env_file")
+			if node == nil {
+				continue
+			}
+			key := fmt.Sprintf("tmux.windows[%d].panes[%d].env_file", i, j)
+			var files StringList
+			if err := node.Decode(&files); err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			for k, file := range files {
+				expanded, err := interpolateEnvVars(file, lookup, strict)
+				if err != nil {
+					return fmt.Errorf("config: %s: %w", key, err)
+				}
+				files[k] = expanded
+			}
+			values, err := readEnvFiles(l.rootDir, files)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			l.scopes[pane] = func(name string) (string, bool) {
+				if v, ok := values[name]; ok {
+					return v, true
+				}
+				return lookup(name)
+			}
+		}
+	}
+	return nil
+}
+
+// loadFile parses a single file and merges it on top of its extends: bases.
+func (l *layerLoader) loadFile(path string) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	if l.loading[absPath] {
+		return nil, fmt.Errorf("config: extends cycle detected at %s", path)
+	}
+	l.loading[absPath] = true
+	defer delete(l.loading, absPath)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, &sourceError{File: path, Line: yamlErrorLine(err), Err: fmt.Errorf("failed to parse config file %s: %w", path, err)}
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse config file %s: top level must be a mapping", path)
+	}
+
+	bases, err := popExtends(root)
+	if err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	if dir := filepath.Dir(absPath); dir != l.rootDir {
+		resolveNodePaths(root, dir)
+	}
+	l.markOrigin(root, path)
+
+	var merged *yaml.Node
+	for _, base := range bases {
+		base, err := interpolateEnvVars(base, osEnvLookup, true)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s: extends: %w", path, err)
+		}
+		if !filepath.IsAbs(base) {
+			base = filepath.Join(filepath.Dir(path), base)
+		}
+		baseNode, err := l.loadFile(base)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, baseNode)
+	}
+	l.files = append(l.files, path)
+
+	return mergeNodes(merged, root), nil
+}
+
+// popExtends removes the extends: key from root and returns its file list.
+func popExtends(root *yaml.Node) ([]string, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != extendsKey {
+			continue
+		}
+		value := root.Content[i+1]
+		root.Content = append(root.Content[:i], root.Content[i+2:]...)
+
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if value.Value == "" {
+				return nil, nil
+			}
+			return []string{value.Value}, nil
+		case yaml.SequenceNode:
+			var files []string
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("extends must be a file path or a list of file paths")
+				}
+				files = append(files, item.Value)
+			}
+			return files, nil
+		default:
+			return nil, fmt.Errorf("extends must be a file path or a list of file paths")
+		}
+	}
+	return nil, nil
+}
+
+// resolveNodePaths makes the relative path values of a config file's root
+// mapping absolute against dir, the directory of that file. Values starting
+// with $ are left for interpolation.
+func resolveNodePaths(root *yaml.Node, dir string) {
+	resolvePathNode(mappingChild(root, "env_file"), dir)
+	tmux := mappingChild(root, "tmux")
+	resolvePathNode(mappingChild(tmux, "config"), dir)
+	windows := mappingChild(tmux, "windows")
+	if windows == nil || windows.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, window := range windows.Content {
+		resolvePathNode(mappingChild(window, "cwd"), dir)
+		panes := mappingChild(window, "panes")
+		if panes == nil || panes.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, pane := range panes.Content {
+			resolvePathNode(mappingChild(pane, "cwd"), dir)
+			resolvePathNode(mappingChild(pane, "env_file"), dir)
+		}
+	}
+}
+
+// resolvePathNode resolves a path scalar, or each item of a list of paths.
+func resolvePathNode(n *yaml.Node, dir string) {
+	if n == nil {
+		return
+	}
+	switch n.Kind {
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			resolvePathNode(item, dir)
+		}
+	case yaml.ScalarNode:
+		if n.Value != "" && !strings.HasPrefix(n.Value, "$") {
+			n.Value = resolvePath(dir, n.Value)
+		}
+	}
+}
+
+// markOrigin records file as the origin of n and every node under it.
+func (l *layerLoader) markOrigin(n *yaml.Node, file string) {
+	l.origins[n] = file
+	for _, child := range n.Content {
+		l.markOrigin(child, file)
+	}
+}
+
+// sources walks the merged tree and lists every leaf value with its origin.
+func (l *layerLoader) sources(n *yaml.Node, key string, out []ValueSource) []ValueSource {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			childKey := n.Content[i].Value
+			if key != "" {
+				childKey = key + "." + childKey
+			}
+			out = l.sources(n.Content[i+1], childKey, out)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			out = l.sources(item, fmt.Sprintf("%s[%d]", key, i), out)
+		}
+	default:
+		out = append(out, ValueSource{Key: key, Value: n.Value, File: l.origins[n]})
+	}
+	return out
+}
+
+// mergeNodes merges overlay on top of base and returns the result.
+// base may be modified in place.
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base == nil {
+		return overlay
+	}
+	if overlay == nil {
+		return base
+	}
+	if base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			if idx := mappingIndex(base, key.Value); idx >= 0 {
+				base.Content[idx+1] = mergeNodes(base.Content[idx+1], value)
+			} else {
+				base.Content = append(base.Content, key, value)
+			}
+		}
+		return base
+	}
+	if base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode && len(overlay.Content) > 0 &&
+		isNamedSequence(base) && isNamedSequence(overlay) {
+		for _, item := range overlay.Content {
+			name := mappingValue(item, "name")
+			matched := false
+			for j, existing := range base.Content {
+				if mappingValue(existing, "name") == name {
+					base.Content[j] = mergeNodes(existing, item)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				base.Content = append(base.Content, item)
+			}
+		}
+		return base
+	}
+	return overlay
+}
+
+// isNamedSequence reports whether every item of a list is a mapping with a
+// non-empty name key.
+func isNamedSequence(n *yaml.Node) bool {
+	for _, item := range n.Content {
+		if item.Kind != yaml.MappingNode || mappingValue(item, "name") == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// mappingIndex returns the index of key in a mapping node's Content, or -1.
+func mappingIndex(n *yaml.Node, key string) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingChild returns the node stored under key, or nil when n is not a
+// mapping or has no such key.
+func mappingChild(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	if idx := mappingIndex(n, key); idx >= 0 {
+		return n.Content[idx+1]
+	}
+	return nil
+}
+
+// mappingValue returns the scalar value stored under key, or "".
+func mappingValue(n *yaml.Node, key string) string {
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+	if idx := mappingIndex(n, key); idx >= 0 && n.Content[idx+1].Kind == yaml.ScalarNode {
+		return n.Content[idx+1].Value
+	}
+	return ""
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestLoad_LocalOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `
+version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: server
+      panes:
+        - name: web
+          cmd: npm run dev
+          log: web.log
+        - name: api
+          cmd: npm run api
+browser:
+  urls:
+    - "http://localhost:3000/*"
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "devlog.local.yml"), `
+tmux:
+  windows:
+    - name: server
+      panes:
+        - name: api
+          cmd: npm run api -- --port 4000
+        - name: worker
+          cmd: npm run worker
+    - name: db
+      panes:
+        - name: psql
+          cmd: psql
+browser:
+  urls:
+    - "http://localhost:4000/*"
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(cfg.Tmux.Windows) != 2 {
+		t.Fatalf("len(Windows) = %d, want 2", len(cfg.Tmux.Windows))
+	}
+	panes := cfg.Tmux.Windows[0].Panes
+	if len(panes) != 3 {
+		t.Fatalf("len(Panes) = %d, want 3", len(panes))
+	}
+	if panes[0].Cmd != "npm run dev" || panes[0].Log != "web.log" {
+		t.Errorf("web pane changed unexpectedly: %+v", panes[0])
+	}
+	if panes[1].Cmd != "npm run api -- --port 4000" {
+		t.Errorf("api Cmd = %q, want override", panes[1].Cmd)
+	}
+	if panes[2].Name != "worker" {
+		t.Errorf("panes[2].Name = %q, want appended worker", panes[2].Name)
+	}
+	if len(cfg.Browser.URLs) != 1 || cfg.Browser.URLs[0] != "http://localhost:4000/*" {
+		t.Errorf("URLs = %v, want local list to replace base list", cfg.Browser.URLs)
+	}
+
+	localPath := filepath.Join(tmpDir, "devlog.local.yml")
+	if got := cfg.SourceOf("tmux.windows[0].panes[1].cmd"); got != localPath {
+		t.Errorf("SourceOf(api cmd) = %q, want %q", got, localPath)
+	}
+	if got := cfg.SourceOf("tmux.windows[0].panes[0].cmd"); got != configPath {
+		t.Errorf("SourceOf(web cmd) = %q, want %q", got, configPath)
+	}
+	if len(cfg.Files) != 2 || cfg.Files[0] != configPath || cfg.Files[1] != localPath {
+		t.Errorf("Files = %v", cfg.Files)
+	}
+}
+
+func TestLoad_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+	basePath := filepath.Join(tmpDir, "base", "devlog.base.yml")
+	writeConfigFile(t, basePath, `
+version: "1.0"
+project: base
+max_runs: 5
+tmux:
+  session: base
+  windows:
+    - name: server
+      panes:
+        - name: web
+          cmd: npm run dev
+`)
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `
+extends: base/devlog.base.yml
+project: app
+tmux:
+  session: app
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.Project != "app" || cfg.Tmux.Session != "app" {
+		t.Errorf("Project/Session = %q/%q, want app/app", cfg.Project, cfg.Tmux.Session)
+	}
+	if cfg.MaxRuns != 5 {
+		t.Errorf("MaxRuns = %d, want 5 from base", cfg.MaxRuns)
+	}
+	if len(cfg.Tmux.Windows) != 1 || cfg.Tmux.Windows[0].Panes[0].Cmd != "npm run dev" {
+		t.Errorf("Windows not inherited from base: %+v", cfg.Tmux.Windows)
+	}
+	if got := cfg.SourceOf("max_runs"); got != basePath {
+		t.Errorf("SourceOf(max_runs) = %q, want %q", got, basePath)
+	}
+}
+
+func TestLoad_ExtendsResolvesPathsAgainstBase(t *testing.T) {
+	tmpDir := t.TempDir()
+	baseDir := filepath.Join(tmpDir, "shared")
+	writeConfigFile(t, filepath.Join(baseDir, ".env"), "PORT=4000\n")
+	writeConfigFile(t, filepath.Join(baseDir, "devlog.base.yml"), `
+version: "1.0"
+project: base
+tmux:
+  session: base
+  windows:
+    - name: server
+      cwd: services
+      panes:
+        - name: web
+          cmd: npm run dev
+          cwd: web
+          env_file: .env
+        - name: api
+          cmd: npm run api
+          cwd: api
+`)
+	configPath := filepath.Join(tmpDir, "app", "devlog.yml")
+	writeConfigFile(t, configPath, `
+extends: ../shared/devlog.base.yml
+tmux:
+  windows:
+    - name: server
+      panes:
+        - name: api
+          cwd: local-api
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	window := cfg.Tmux.Windows[0]
+	if want := filepath.Join(baseDir, "services"); window.Cwd != want {
+		t.Errorf("window Cwd = %q, want %q", window.Cwd, want)
+	}
+	if want := filepath.Join(baseDir, "web"); window.Panes[0].Cwd != want {
+		t.Errorf("web Cwd = %q, want %q", window.Panes[0].Cwd, want)
+	}
+	if got := window.Panes[0].Env["PORT"]; got != "4000" {
+		t.Errorf("web env PORT = %q, want env_file from the base directory", got)
+	}
+	if want := filepath.Join(tmpDir, "app", "local-api"); window.Panes[1].Cwd != want {
+		t.Errorf("api Cwd = %q, want %q from the overriding file", window.Panes[1].Cwd, want)
+	}
+}
+
+func TestLoad_EmptyListReplacesNamedList(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "devlog.base.yml"), `
+version: "1.0"
+project: base
+tmux:
+  session: base
+  windows:
+    - name: server
+      panes:
+        - name: web
+          cmd: npm run dev
+`)
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `
+extends: devlog.base.yml
+tmux:
+  windows: []
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "devlog.local.yml"), `
+tmux:
+  windows:
+    - name: db
+      panes:
+        - name: psql
+          cmd: psql
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if len(cfg.Tmux.Windows) != 1 || cfg.Tmux.Windows[0].Name != "db" {
+		t.Errorf("Windows = %+v, want only db after windows: [] cleared the base list", cfg.Tmux.Windows)
+	}
+}
+
+func TestLoad_ExtendsCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "a.yml"), "extends: b.yml\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "b.yml"), "extends: a.yml\n")
+
+	_, err := Load(filepath.Join(tmpDir, "a.yml"))
+	if err == nil {
+		t.Fatal("Load() expected cycle error, got nil")
+	}
+	if !strings.Contains(err.Error(), "extends cycle") {
+		t.Errorf("Load() error = %q, want extends cycle", err.Error())
+	}
+}
+
+func TestLocalOverridePath(t *testing.T) {
+	if got := LocalOverridePath("/p/devlog.yml"); got != "/p/devlog.local.yml" {
+		t.Errorf("LocalOverridePath() = %q", got)
+	}
+	if got := LocalOverridePath("/p/devlog.e2e.yaml"); got != "/p/devlog.e2e.local.yaml" {
+		t.Errorf("LocalOverridePath() = %q", got)
+	}
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config schema version written by this devlog.
+// Older versions are upgraded in memory by Load and on disk by `devlog migrate`.
+const CurrentVersion = "2"
+
+// migration upgrades a config tree from one schema version to the next.
+// apply edits the raw (uninterpolated) YAML tree in place and returns a
+// human-readable line per change it made.
+type migration struct {
+	from  string
+	to    string
+	apply func(root *yaml.Node) []string
+}
+
+// migrations are applied in order, starting at the file's version.
+var migrations = []migration{
+	{from: "1", to: "2", apply: migrateV1ToV2},
+}
+
+// migrateV1ToV2 drops browser.native_host, which early docs showed but devlog
+// never read (browser capture is enabled by browser.urls).
+func migrateV1ToV2(root *yaml.Node) []string {
+	var changes []string
+	if deleteKey(root, "browser.native_host") {
+		changes = append(changes, "removed browser.native_host (browser capture is enabled by browser.urls)")
+	}
+	return changes
+}
+
+// NormalizeVersion maps a version spelling to its major version:
+// 1, "1", "1.0" and "1.0.0" all become "1". Returns "" if v is not numeric.
+func NormalizeVersion(v string) string {
+	major, rest, _ := strings.Cut(strings.TrimSpace(v), ".")
+	n, err := strconv.Atoi(major)
+	if err != nil || n < 0 {
+		return ""
+	}
+	if strings.Trim(strings.ReplaceAll(rest, ".", ""), "0") != "" {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// supportedVersion reports whether v is a known schema version.
+func supportedVersion(v string) bool {
+	v = NormalizeVersion(v)
+	if v == CurrentVersion {
+		return true
+	}
+	for _, m := range migrations {
+		if m.from == v {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateNode upgrades root to CurrentVersion. It returns the original
+// normalized version and the list of changes. Files without a version are left
+// alone (Validate reports the missing key). The version value itself is only
+// rewritten when bumpVersion is true, so Load keeps the spelling from the file.
+func migrateNode(root *yaml.Node, bumpVersion bool) (string, []string, error) {
+	idx := mappingIndex(root, "version")
+	if idx < 0 {
+		return "", nil, nil
+	}
+	versionNode := root.Content[idx+1]
+	raw := versionNode.Value
+	version := NormalizeVersion(raw)
+	if version == "" {
+		return "", nil, fmt.Errorf("config: version '%s' is not a valid schema version", raw)
+	}
+	current, _ := strconv.Atoi(CurrentVersion)
+	if n, _ := strconv.Atoi(version); n > current {
+		return version, nil, fmt.Errorf("config: version %s is newer than this devlog supports (%s); upgrade devlog", version, CurrentVersion)
+	}
+
+	from := version
+	var changes []string
+	for _, m := range migrations {
+		if m.from != version {
+			continue
+		}
+		for _, change := range m.apply(root) {
+			changes = append(changes, fmt.Sprintf("v%s -> v%s: %s", m.from, m.to, change))
+		}
+		version = m.to
+	}
+
+	if bumpVersion && (raw != CurrentVersion || versionNode.Tag != "!!str") {
+		versionNode.Value = CurrentVersion
+		versionNode.Tag = "!!str"
+		versionNode.Style = yaml.DoubleQuotedStyle
+		changes = append(changes, fmt.Sprintf("set version to \"%s\" (was %s)", CurrentVersion, raw))
+	}
+	return from, changes, nil
+}
+
+// MigrateFile upgrades the config file at path to CurrentVersion and returns
+// the rewritten YAML with the list of changes. Comments are preserved. When
+// write is true and there are changes, the file is replaced in place.
+func MigrateFile(path string, write bool) ([]byte, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: top level must be a mapping", path)
+	}
+	if mappingIndex(doc.Content[0], "version") < 0 {
+		version, source, err := inheritedVersion(path)
+		switch {
+		case err != nil:
+			return nil, nil, err
+		case source == "":
+			return nil, nil, fmt.Errorf("config: %s has no version key", path)
+		case NormalizeVersion(version) == CurrentVersion:
+			return data, nil, nil
+		default:
+			return nil, nil, fmt.Errorf("config: %s takes version %s from %s; migrate that file instead", path, version, source)
+		}
+	}
+
+	_, changes, err := migrateNode(doc.Content[0], true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode migrated config: %w", err)
+	}
+	out := buf.Bytes()
+
+	if write {
+		if err := writeFileAtomic(path, out); err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, changes, nil
+}
+
+// inheritedVersion returns the version path gets through its extends: chain
+// and the file that sets it, or "" for both when no file in the chain does.
+func inheritedVersion(path string) (string, string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	loader := newLayerLoader()
+	loader.rootDir = filepath.Dir(absPath)
+	merged, err := loader.loadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	idx := mappingIndex(merged, "version")
+	if idx < 0 {
+		return "", "", nil
+	}
+	node := merged.Content[idx+1]
+	return node.Value, loader.origins[node], nil
+}
+
+// writeFileAtomic replaces path with data via a temp file + rename, keeping
+// the original file mode.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// deleteKey removes the key at a dotted mapping path. Reports whether it existed.
+func deleteKey(root *yaml.Node, path string) bool {
+	parent, key := parentMapping(root, path)
+	if parent == nil {
+		return false
+	}
+	idx := mappingIndex(parent, key)
+	if idx < 0 {
+		return false
+	}
+	parent.Content = append(parent.Content[:idx], parent.Content[idx+2:]...)
+	return true
+}
+
+// parentMapping walks a dotted path and returns the mapping holding its last
+// segment, plus that segment. Returns nil if an intermediate key is missing.
+func parentMapping(root *yaml.Node, path string) (*yaml.Node, string) {
+	segments := strings.Split(path, ".")
+	n := root
+	for _, seg := range segments[:len(segments)-1] {
+		i := mappingIndex(n, seg)
+		if i < 0 || n.Content[i+1].Kind != yaml.MappingNode {
+			return nil, ""
+		}
+		n = n.Content[i+1]
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil, ""
+	}
+	return n, segments[len(segments)-1]
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := map[string]string{
+		"1":     "1",
+		"1.0":   "1",
+		"1.0.0": "1",
+		"2":     "2",
+		"1.1":   "",
+		"v1":    "",
+		"":      "",
+	}
+	for in, want := range tests {
+		if got := NormalizeVersion(in); got != want {
+			t.Errorf("NormalizeVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	original := `# Team devlog config
+version: 1
+project: myapp # the app
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: !raw echo $HOME
+browser:
+  native_host: true
+  urls:
+    - "http://localhost:*/*"
+`
+	writeConfigFile(t, configPath, original)
+
+	out, changes, err := MigrateFile(configPath, false)
+	if err != nil {
+		t.Fatalf("MigrateFile() failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("changes = %v, want native_host removal and version bump", changes)
+	}
+	data, _ := os.ReadFile(configPath)
+	if string(data) != original {
+		t.Error("MigrateFile(write=false) modified the file")
+	}
+
+	for _, want := range []string{"# Team devlog config", `version: "2"`, "project: myapp # the app", "cmd: !raw echo $HOME"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("migrated output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "native_host") {
+		t.Errorf("migrated output still has native_host:\n%s", out)
+	}
+
+	if _, _, err := MigrateFile(configPath, true); err != nil {
+		t.Fatalf("MigrateFile(write=true) failed: %v", err)
+	}
+	data, _ = os.ReadFile(configPath)
+	if string(data) != string(out) {
+		t.Errorf("written file differs from dry-run output")
+	}
+
+	// Already current: no changes, file untouched
+	_, changes, err = MigrateFile(configPath, true)
+	if err != nil {
+		t.Fatalf("MigrateFile() on current file failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes = %v, want none for current version", changes)
+	}
+}
+
+func TestLoad_MigratesOldVersionInMemory(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo ok
+browser:
+  native_host: true
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if cfg.MigratedFrom != "1" {
+		t.Errorf("MigratedFrom = %q, want %q", cfg.MigratedFrom, "1")
+	}
+	if problems := CheckFile(configPath); len(problems) != 0 {
+		t.Errorf("CheckFile() = %v, want v1 file to validate after in-memory migration", problems)
+	}
+}
+
+func TestLoad_RejectsNewerVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "99"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo ok
+`)
+
+	_, err := Load(configPath)
+	if err == nil || !strings.Contains(err.Error(), "newer than this devlog supports") {
+		t.Errorf("Load() error = %v, want newer version error", err)
+	}
+}
+
+func TestMigrateFile_VersionFromExtends(t *testing.T) {
+	tmpDir := t.TempDir()
+	basePath := filepath.Join(tmpDir, "devlog.base.yml")
+	writeConfigFile(t, basePath, "version: 1\nproject: base\n")
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, "extends: devlog.base.yml\ntmux:\n  session: dev\n")
+
+	_, _, err := MigrateFile(configPath, true)
+	if err == nil || !strings.Contains(err.Error(), "takes version 1 from "+basePath) {
+		t.Fatalf("MigrateFile() error = %v, want pointer to the base file", err)
+	}
+
+	if _, _, err := MigrateFile(basePath, true); err != nil {
+		t.Fatalf("MigrateFile(base) failed: %v", err)
+	}
+	_, changes, err := MigrateFile(configPath, true)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("MigrateFile() after migrating the base = %v, %v; want no changes", changes, err)
+	}
+}
+
+func TestDeleteKey(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("a:\n  keep: 1\n  gone: 2\nb: 3\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Content[0]
+
+	if !deleteKey(root, "a.gone") {
+		t.Error("deleteKey() = false, want true")
+	}
+	if deleteKey(root, "missing.key") {
+		t.Error("deleteKey(missing) = true, want false")
+	}
+
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a:\n    keep: 1\nb: 3\n"
+	if string(out) != want {
+		t.Errorf("migrated tree =\n%s\nwant\n%s", out, want)
+	}
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Readiness defaults, used when a probe leaves timeout or interval unset.
+const (
+	DefaultReadyTimeout  = 60 * time.Second
+	DefaultReadyInterval = 500 * time.Millisecond
+)
+
+// ReadyConfig describes how to tell that a pane's process is ready.
+// Exactly one of TCP, HTTP or Log is set when the probe is enabled.
+type ReadyConfig struct {
+	TCP      string `yaml:"tcp"`      // port or host:port that must accept connections
+	HTTP     string `yaml:"http"`     // URL polled with GET
+	Status   int    `yaml:"status"`   // expected HTTP status; any 2xx/3xx when 0
+	Log      string `yaml:"log"`      // regex that must appear in the pane's log file
+	Timeout  string `yaml:"timeout"`  // how long to wait, e.g. "30s" (default 60s)
+	Interval string `yaml:"interval"` // delay between checks (default 500ms)
+}
+
+// Enabled reports whether a probe is configured.
+func (r ReadyConfig) Enabled() bool {
+	return r.TCP != "" || r.HTTP != "" || r.Log != ""
+}
+
+// TCPAddress returns the host:port to dial; a bare port means localhost.
+func (r ReadyConfig) TCPAddress() string {
+	if _, err := strconv.Atoi(r.TCP); err == nil {
+		return net.JoinHostPort("localhost", r.TCP)
+	}
+	return r.TCP
+}
+
+// TimeoutDuration returns the probe timeout, or DefaultReadyTimeout.
+func (r ReadyConfig) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(r.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultReadyTimeout
+}
+
+// IntervalDuration returns the delay between checks, or DefaultReadyInterval.
+func (r ReadyConfig) IntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(r.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultReadyInterval
+}
+
+// validateReadiness checks depends_on references (known panes, no cycles)
+// and every pane's ready probe.
+func (c *Config) validateReadiness() []FieldError {
+	var problems []FieldError
+	add := func(path, format string, args ...any) {
+		problems = append(problems, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	known := make(map[string]bool)
+	for _, window := range c.Tmux.Windows {
+		for _, pane := range window.Panes {
+			if pane.Name != "" {
+				known[pane.Name] = true
+			}
+		}
+	}
+
+	for i, window := range c.Tmux.Windows {
+		for j, pane := range window.Panes {
+			panePath := fmt.Sprintf("tmux.windows[%d].panes[%d]", i, j)
+			for _, dep := range pane.DependsOn {
+				switch {
+				case dep == pane.Name:
+					add(panePath+".depends_on", "must not reference the pane itself")
+				case !known[dep]:
+					add(panePath+".depends_on", "references unknown pane '%s'", dep)
+				}
+			}
+			problems = append(problems, validateReady(panePath+".ready", pane)...)
+		}
+	}
+
+	if cycle := c.dependencyCycle(); len(cycle) > 0 {
+		add("tmux.windows", "have a depends_on cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return problems
+}
+
+func validateReady(path string, pane PaneConfig) []FieldError {
+	var problems []FieldError
+	add := func(key, format string, args ...any) {
+		field := path
+		if key != "" {
+			field += "." + key
+		}
+		problems = append(problems, FieldError{Path: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ready := pane.Ready
+	kinds := 0
+	for _, v := range []string{ready.TCP, ready.HTTP, ready.Log} {
+		if v != "" {
+			kinds++
+		}
+	}
+	if kinds == 0 {
+		if ready != (ReadyConfig{}) {
+			add("", "must set one of tcp, http or log")
+		}
+		return problems
+	}
+	if kinds > 1 {
+		add("", "must set only one of tcp, http or log")
+	}
+
+	if ready.TCP != "" {
+		if _, port, err := net.SplitHostPort(ready.TCPAddress()); err != nil || port == "" {
+			add("tcp", "must be a port or host:port, got '%s'", ready.TCP)
+		}
+	}
+	if ready.HTTP != "" {
+		u, err := url.Parse(ready.HTTP)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("http", "must be an http:// or https:// URL, got '%s'", ready.HTTP)
+		}
+	}
+	if ready.Status != 0 {
+		if ready.HTTP == "" {
+			add("status", "requires http")
+		} else if ready.Status < 100 || ready.Status > 599 {
+			add("status", "must be an HTTP status code, got %d", ready.Status)
+		}
+	}
+	if ready.Log != "" {
+		if pane.Log == "" {
+			add("log", "requires the pane to set log")
+		}
+		if _, err := regexp.Compile(ready.Log); err != nil {
+			add("log", "is not a valid regular expression: %v", err)
+		}
+	}
+	for _, field := range []struct{ key, value string }{{"timeout", ready.Timeout}, {"interval", ready.Interval}} {
+		if field.value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(field.value); err != nil || d <= 0 {
+			add(field.key, "must be a positive duration like '30s', got '%s'", field.value)
+		}
+	}
+	return problems
+}
+
+// dependencyCycle returns the pane names forming a depends_on cycle, with the
+// first name repeated at the end, or nil when the graph is acyclic.
+func (c *Config) dependencyCycle() []string {
+	deps := make(map[string][]string)
+	var names []string
+	for _, window := range c.Tmux.Windows {
+		for _, pane := range window.Panes {
+			if pane.Name != "" {
+				deps[pane.Name] = pane.DependsOn
+				names = append(names, pane.Name)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range deps[name] {
+			if dep == name {
+				continue // reported as a self-reference
+			}
+			switch state[dep] {
+			case visiting:
+				for k, n := range stack {
+					if n == dep {
+						return append(append([]string{}, stack[k:]...), dep)
+					}
+				}
+			case unvisited:
+				if _, ok := deps[dep]; !ok {
+					continue
+				}
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		return nil
+	}
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readyTestConfig(panes ...PaneConfig) *Config {
+	return &Config{
+		Version: CurrentVersion,
+		Project: "test",
+		RunMode: "timestamped",
+		Tmux: TmuxConfig{
+			Session: "test",
+			Windows: []WindowConfig{{Name: "main", Panes: panes}},
+		},
+	}
+}
+
+func TestValidate_Readiness(t *testing.T) {
+	tests := []struct {
+		name  string
+		panes []PaneConfig
+		want  string
+	}{
+		{
+			name: "unknown dependency",
+			panes: []PaneConfig{
+				{Name: "api", Cmd: "run", DependsOn: StringList{"postgres"}},
+			},
+			want: "config: tmux.windows[0].panes[0].depends_on references unknown pane 'postgres'",
+		},
+		{
+			name: "self dependency",
+			panes: []PaneConfig{
+				{Name: "api", Cmd: "run", DependsOn: StringList{"api"}},
+			},
+			want: "config: tmux.windows[0].panes[0].depends_on must not reference the pane itself",
+		},
+		{
+			name: "cycle",
+			panes: []PaneConfig{
+				{Name: "a", Cmd: "run", DependsOn: StringList{"b"}},
+				{Name: "b", Cmd: "run", DependsOn: StringList{"c"}},
+				{Name: "c", Cmd: "run", DependsOn: StringList{"a"}},
+			},
+			want: "config: tmux.windows have a depends_on cycle: a -> b -> c -> a",
+		},
+		{
+			name:  "no probe kind",
+			panes: []PaneConfig{{Name: "db", Cmd: "run", Ready: ReadyConfig{Timeout: "5s"}}},
+			want:  "config: tmux.windows[0].panes[0].ready must set one of tcp, http or log",
+		},
+		{
+			name:  "two probe kinds",
+			panes: []PaneConfig{{Name: "db", Cmd: "run", Ready: ReadyConfig{TCP: "5432", HTTP: "http://localhost"}}},
+			want:  "config: tmux.windows[0].panes[0].ready must set only one of tcp, http or log",
+		},
+		{
+			name:  "bad tcp",
+			panes: []PaneConfig{{Name: "db", Cmd: "run", Ready: ReadyConfig{TCP: "localhost"}}},
+			want:  "config: tmux.windows[0].panes[0].ready.tcp must be a port or host:port, got 'localhost'",
+		},
+		{
+			name:  "bad url",
+			panes: []PaneConfig{{Name: "web", Cmd: "run", Ready: ReadyConfig{HTTP: "localhost:3000"}}},
+			want:  "config: tmux.windows[0].panes[0].ready.http must be an http:// or https:// URL, got 'localhost:3000'",
+		},
+		{
+			name:  "log without pane log",
+			panes: []PaneConfig{{Name: "db", Cmd: "run", Ready: ReadyConfig{Log: "ready"}}},
+			want:  "config: tmux.windows[0].panes[0].ready.log requires the pane to set log",
+		},
+		{
+			name:  "bad regex",
+			panes: []PaneConfig{{Name: "db", Cmd: "run", Log: "db.log", Ready: ReadyConfig{Log: "ready("}}},
+			want:  "config: tmux.windows[0].panes[0].ready.log is not a valid regular expression",
+		},
+		{
+			name:  "bad timeout",
+			panes: []PaneConfig{{Name: "db", Cmd: "run", Ready: ReadyConfig{TCP: "5432", Timeout: "30"}}},
+			want:  "config: tmux.windows[0].panes[0].ready.timeout must be a positive duration like '30s', got '30'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readyTestConfig(tt.panes...).Validate()
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.want)
+			}
+			if !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %q, want %q", err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate_ReadinessValid(t *testing.T) {
+	cfg := readyTestConfig(
+		PaneConfig{Name: "db", Cmd: "postgres", Log: "db.log", Ready: ReadyConfig{Log: "ready to accept connections"}},
+		PaneConfig{Name: "api", Cmd: "run", DependsOn: StringList{"db"}, Ready: ReadyConfig{HTTP: "http://localhost:8080/health", Status: 204}},
+		PaneConfig{Name: "web", Cmd: "run", DependsOn: StringList{"db", "api"}, Ready: ReadyConfig{TCP: "127.0.0.1:3000", Timeout: "2m", Interval: "1s"}},
+	)
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() failed: %v", err)
+	}
+}
+
+func TestReadyConfig_Defaults(t *testing.T) {
+	var ready ReadyConfig
+	if ready.Enabled() {
+		t.Error("Enabled() = true for empty ReadyConfig")
+	}
+	if got := ready.TimeoutDuration(); got != DefaultReadyTimeout {
+		t.Errorf("TimeoutDuration() = %s, want %s", got, DefaultReadyTimeout)
+	}
+	if got := ready.IntervalDuration(); got != DefaultReadyInterval {
+		t.Errorf("IntervalDuration() = %s, want %s", got, DefaultReadyInterval)
+	}
+
+	ready = ReadyConfig{TCP: "5432", Timeout: "90s"}
+	if got := ready.TCPAddress(); got != "localhost:5432" {
+		t.Errorf("TCPAddress() = %q, want %q", got, "localhost:5432")
+	}
+	if got := ready.TimeoutDuration(); got != 90*time.Second {
+		t.Errorf("TimeoutDuration() = %s, want 90s", got)
+	}
+}
+
+func TestLoad_DependsOnAndReady(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "2"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - name: db
+          cmd: postgres
+          ready:
+            tcp: 5432
+            timeout: 30s
+        - name: api
+          cmd: go run .
+          depends_on: db
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	panes := cfg.Tmux.Windows[0].Panes
+	if panes[0].Ready.TCP != "5432" || panes[0].Ready.TimeoutDuration() != 30*time.Second {
+		t.Errorf("db Ready = %+v", panes[0].Ready)
+	}
+	if len(panes[1].DependsOn) != 1 || panes[1].DependsOn[0] != "db" {
+		t.Errorf("api DependsOn = %v, want [db]", panes[1].DependsOn)
+	}
+}
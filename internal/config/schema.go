@@ -0,0 +1,348 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a config issue with its position in a source file.
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (p Problem) String() string {
+	switch {
+	case p.Line > 0 && p.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Column, p.Message)
+	case p.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	default:
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	}
+}
+
+// sourceError is an error tied to a position in a file other than the merged
+// config tree: a YAML syntax error or a dotenv parse error.
+type sourceError struct {
+	File string
+	Line int // 0 when unknown
+	Err  error
+}
+
+func (e *sourceError) Error() string { return e.Err.Error() }
+func (e *sourceError) Unwrap() error { return e.Err }
+
+// yamlLineRegex matches the position yaml.v3 puts in its syntax errors.
+var yamlLineRegex = regexp.MustCompile(`^yaml: line (\d+):`)
+
+// yamlErrorLine returns the line of an error returned by yaml.Unmarshal, or 0.
+func yamlErrorLine(err error) int {
+	text := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		text = "yaml: " + typeErr.Errors[0]
+	}
+	if m := yamlLineRegex.FindStringSubmatch(text); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return line
+	}
+	return 0
+}
+
+// CheckFile loads path (with its extends: bases and local override) and reports
+// every problem with file:line:col positions: YAML syntax errors, unknown keys,
+// values of the wrong type and the semantic checks done by Validate.
+func CheckFile(path string) []Problem {
+	loader := newLayerLoader()
+	merged, err := loader.loadLayers(path)
+	if err != nil {
+		return []Problem{errorProblem(path, err)}
+	}
+
+	var problems []Problem
+	report := func(n *yaml.Node, format string, args ...any) {
+		problems = append(problems, loader.problemAt(n, path, fmt.Sprintf(format, args...)))
+	}
+	checkNode(merged, reflect.TypeOf(Config{}), "", report)
+
+	cfg, err := decodeMerged(path, loader, merged)
+	var typeErr *yaml.TypeError
+	switch {
+	case errors.As(err, &typeErr) && len(problems) > 0:
+		// Already reported by checkNode; the config can't be decoded for
+		// the semantic checks
+		return problems
+	case err != nil:
+		return append(problems, errorProblem(path, err))
+	}
+	for _, fe := range cfg.Problems() {
+		problems = append(problems, loader.problemAt(lookupPath(merged, fe.Path), path, fe.Path+" "+fe.Message))
+	}
+	return problems
+}
+
+// errorProblem reports a load error against the file it comes from, at its
+// line when known, and against path otherwise.
+func errorProblem(path string, err error) Problem {
+	problem := Problem{File: path, Message: err.Error()}
+	var se *sourceError
+	if errors.As(err, &se) && se.File != "" {
+		problem.File, problem.Line = se.File, se.Line
+	}
+	return problem
+}
+
+// problemAt builds a Problem positioned at n, attributed to the file n came from.
+func (l *layerLoader) problemAt(n *yaml.Node, fallbackFile, message string) Problem {
+	file := l.origins[n]
+	if file == "" {
+		file = fallbackFile
+	}
+	return Problem{File: file, Line: n.Line, Column: n.Column, Message: message}
+}
+
+var stringListType = reflect.TypeOf(StringList{})
+
+// checkNode compares n against the Go type t, reporting unknown keys and
+// values whose YAML kind or scalar type does not fit.
+func checkNode(n *yaml.Node, t reflect.Type, path string, report func(n *yaml.Node, format string, args ...any)) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+		return
+	}
+	where := path
+	if where == "" {
+		where = "top level"
+	}
+
+	if t == stringListType {
+		if n.Kind == yaml.ScalarNode {
+			return
+		}
+		if n.Kind != yaml.SequenceNode {
+			report(n, "%s must be a string or a list of strings", where)
+			return
+		}
+		for i, item := range n.Content {
+			checkNode(item, reflect.TypeOf(""), fmt.Sprintf("%s[%d]", path, i), report)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			report(n, "%s must be a mapping", where)
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				msg := fmt.Sprintf("unknown key '%s' in %s", key.Value, where)
+				if suggestion := closestName(key.Value, fields); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+				}
+				report(key, "%s", msg)
+				continue
+			}
+			checkNode(value, field.Type, joinPath(path, key.Value), report)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			report(n, "%s must be a mapping", where)
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			checkNode(n.Content[i+1], t.Elem(), joinPath(path, n.Content[i].Value), report)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			report(n, "%s must be a list", where)
+			return
+		}
+		for i, item := range n.Content {
+			checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	case reflect.String:
+		if n.Kind != yaml.ScalarNode {
+			report(n, "%s must be a string", where)
+		}
+	case reflect.Int:
+		var v int
+		if n.Kind != yaml.ScalarNode || n.Decode(&v) != nil {
+			report(n, "%s must be an integer", where)
+		}
+	case reflect.Bool:
+		var v bool
+		if n.Kind != yaml.ScalarNode || n.Decode(&v) != nil {
+			report(n, "%s must be true or false", where)
+		}
+	}
+}
+
+// yamlFields maps yaml key names to struct fields, skipping `yaml:"-"`.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// closestName suggests a known key within edit distance 2 of name.
+func closestName(name string, fields map[string]reflect.StructField) string {
+	best, bestDist := "", 3
+	for candidate := range fields {
+		if d := editDistance(name, candidate); d < bestDist || (d == bestDist && candidate < best) {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// pathSegmentRegex splits "tmux.windows[1].panes[0].cmd" into keys and indexes.
+var pathSegmentRegex = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// lookupPath returns the node at a Validate key path, or its deepest existing
+// ancestor when the key itself is missing.
+func lookupPath(root *yaml.Node, path string) *yaml.Node {
+	n := root
+	for _, seg := range pathSegmentRegex.FindAllString(path, -1) {
+		var next *yaml.Node
+		if strings.HasPrefix(seg, "[") {
+			idx, _ := strconv.Atoi(strings.Trim(seg, "[]"))
+			if n.Kind == yaml.SequenceNode && idx < len(n.Content) {
+				next = n.Content[idx]
+			}
+		} else if n.Kind == yaml.MappingNode {
+			if i := mappingIndex(n, seg); i >= 0 {
+				next = n.Content[i+1]
+			}
+		}
+		if next == nil {
+			return n
+		}
+		n = next
+	}
+	return n
+}
+
+// schemaOverrides replaces generated JSON Schema fragments for specific keys.
+// Paths use "[]" for list items, e.g. "tmux.windows[].panes[].cmd".
+var schemaOverrides = map[string]map[string]any{
+	"version":                           {"type": []string{"string", "number"}, "description": "Config schema version"},
+	"run_mode":                          {"type": "string", "enum": []string{"timestamped", "overwrite"}},
+	"browser.format":                    {"type": "string", "enum": []string{"text", "jsonl", "logfmt"}},
+	"tmux.windows[].panes[].restart":    {"type": "string", "enum": []string{"never", "on-failure", "always"}},
+	"tmux.windows[].panes[].split":      {"type": "string", "enum": []string{"h", "v"}},
+	"tmux.windows[].panes[].timestamps": {"type": "string", "enum": []string{"none", "rfc3339", "relative"}},
+	"tmux.windows[].panes[].size":       {"type": "integer", "minimum": 1, "maximum": 99},
+}
+
+// schemaExtraProperties lists top-level keys handled before decoding.
+var schemaExtraProperties = map[string]map[string]any{
+	extendsKey: stringListSchema(),
+}
+
+// JSONSchema returns a JSON Schema (draft-07) describing devlog.yml, suitable
+// for editor autocompletion via yaml-language-server.
+func JSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(Config{}), "")
+	props := schema["properties"].(map[string]any)
+	for name, s := range schemaExtraProperties {
+		props[name] = s
+	}
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "devlog.yml"
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func typeSchema(t reflect.Type, path string) map[string]any {
+	if override, ok := schemaOverrides[path]; ok {
+		return override
+	}
+	if t == stringListType {
+		return stringListSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		props := make(map[string]any)
+		for name, f := range yamlFields(t) {
+			props[name] = typeSchema(f.Type, joinPath(path, name))
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), joinPath(path, "*"))}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), path+"[]")}
+	case reflect.Int:
+		return map[string]any{"type": "integer"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+func stringListSchema() map[string]any {
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckFile_ReportsPositions(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "1.0"
+project: myapp
+retension_days: 7
+max_runs: lots
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo ok
+          colour: red
+`)
+
+	problems := CheckFile(configPath)
+	want := []string{
+		configPath + ":3:1: unknown key 'retension_days' in top level (did you mean 'retention_days'?)",
+		configPath + ":4:11: max_runs must be an integer",
+		configPath + ":11:11: unknown key 'colour' in tmux.windows[0].panes[0]",
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckFile() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckFile_SemanticProblems(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo ok
+`)
+	localPath := filepath.Join(tmpDir, "devlog.local.yml")
+	writeConfigFile(t, localPath, `run_mode: sometimes
+tmux:
+  windows:
+    - name: extra
+      panes:
+        - log: extra.log
+`)
+
+	problems := CheckFile(configPath)
+	want := []string{
+		localPath + ":6:11: tmux.windows[1].panes[0].cmd is required",
+		localPath + ":1:11: run_mode must be 'timestamped' or 'overwrite', got 'sometimes'",
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckFile() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckFile_UnknownKeysAndSemanticProblems(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - comd: echo ok
+          split: x
+`)
+
+	problems := CheckFile(configPath)
+	want := []string{
+		configPath + ":8:11: unknown key 'comd' in tmux.windows[0].panes[0] (did you mean 'cmd'?)",
+		configPath + ":8:11: tmux.windows[0].panes[0].cmd is required",
+		configPath + ":9:18: tmux.windows[0].panes[0].split must be 'h' or 'v', got 'x'",
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CheckFile() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckFile_DotenvErrorPosition(t *testing.T) {
+	tmpDir := t.TempDir()
+	envPath := filepath.Join(tmpDir, ".env")
+	writeConfigFile(t, envPath, "A=1\nB=2\nnot a pair\n")
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, `version: "1.0"
+project: myapp
+env_file: .env
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo ok
+`)
+
+	problems := CheckFile(configPath)
+	if len(problems) != 1 || problems[0].File != envPath || problems[0].Line != 3 {
+		t.Errorf("CheckFile() = %+v, want one problem at %s:3", problems, envPath)
+	}
+}
+
+func TestCheckFile_SyntaxErrorAndValid(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "devlog.yml")
+	writeConfigFile(t, configPath, "version: \"1.0\"\nproject: [unclosed\n")
+
+	problems := CheckFile(configPath)
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Fatalf("CheckFile() = %+v, want one positioned syntax error", problems)
+	}
+
+	writeConfigFile(t, configPath, `version: "1.0"
+project: myapp
+tmux:
+  session: dev
+  windows:
+    - name: main
+      panes:
+        - cmd: echo ok
+`)
+	if problems := CheckFile(configPath); len(problems) != 0 {
+		t.Errorf("CheckFile() = %+v, want no problems", problems)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() failed: %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		t.Fatalf("JSONSchema() is not valid JSON: %v", err)
+	}
+	props := doc["properties"].(map[string]any)
+	for _, key := range []string{"version", "project", "tmux", "browser", "profiles", "extends", "env_file"} {
+		if _, ok := props[key]; !ok {
+			t.Errorf("schema missing top-level property %q", key)
+		}
+	}
+	if _, ok := props["Dir"]; ok {
+		t.Error("schema should not expose yaml:\"-\" fields")
+	}
+
+	// The published schema must stay in sync with the Go types
+	published, err := os.ReadFile(filepath.Join("..", "..", "devlog.schema.json"))
+	if err != nil {
+		t.Fatalf("failed to read published schema: %v", err)
+	}
+	if !bytes.Equal(published, schema) {
+		t.Error("devlog.schema.json is out of date; run: just schema")
+	}
+}
@@ -0,0 +1,89 @@
+// Package hooks runs the shell commands configured under hooks: in devlog.yml
+// and records their output in the run directory's hooks.log.
+package hooks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// LogFile is the name of the hook log inside a run directory.
+const LogFile = "hooks.log"
+
+// Runner runs hook commands for one devlog session.
+type Runner struct {
+	Dir     string            // working directory for hook commands (the config directory)
+	LogsDir string            // run directory; exported as DEVLOG_LOGS_DIR and home of hooks.log
+	Env     map[string]string // extra variables, e.g. DEVLOG_SESSION
+	Stdout  io.Writer         // receives one progress line per command (optional)
+}
+
+// Run executes commands in order for the named stage (e.g. "pre_up") and
+// stops at the first failure. Output of every command is appended to
+// hooks.log between header and footer lines.
+func (r Runner) Run(stage string, commands []string) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	// Sessions started by older versions may not record a run directory
+	var logFile io.Writer = io.Discard
+	logPath := "no hook log"
+	if r.LogsDir != "" {
+		logPath = filepath.Join(r.LogsDir, LogFile)
+		if err := os.MkdirAll(r.LogsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create logs directory: %w", err)
+		}
+		f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open hook log: %w", err)
+		}
+		defer f.Close()
+		logFile = f
+	}
+
+	stdout := r.Stdout
+	if stdout == nil {
+		stdout = io.Discard
+	}
+
+	for _, command := range commands {
+		fmt.Fprintf(stdout, "Running %s hook: %s\n", stage, command)
+		fmt.Fprintf(logFile, "==> %s %s: %s\n", time.Now().UTC().Format(time.RFC3339), stage, command)
+
+		start := time.Now()
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = r.Dir
+		cmd.Env = r.environ(stage)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		runErr := cmd.Run()
+
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if runErr != nil {
+			fmt.Fprintf(logFile, "==> %s hook failed after %s: %v\n", stage, elapsed, runErr)
+			return fmt.Errorf("%s hook '%s' failed: %w (see %s)", stage, command, runErr, logPath)
+		}
+		fmt.Fprintf(logFile, "==> %s hook finished in %s\n", stage, elapsed)
+	}
+	return nil
+}
+
+// environ returns the process environment plus the devlog hook variables.
+func (r Runner) environ(stage string) []string {
+	env := os.Environ()
+	if r.LogsDir != "" {
+		if absLogsDir, err := filepath.Abs(r.LogsDir); err == nil {
+			env = append(env, "DEVLOG_LOGS_DIR="+absLogsDir)
+		}
+	}
+	env = append(env, "DEVLOG_HOOK="+stage)
+	for name, value := range r.Env {
+		env = append(env, name+"="+value)
+	}
+	return env
+}
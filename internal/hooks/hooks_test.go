@@ -0,0 +1,83 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunner_Run(t *testing.T) {
+	workDir := t.TempDir()
+	logsDir := filepath.Join(t.TempDir(), "20260102-150405")
+	var stdout strings.Builder
+	r := Runner{
+		Dir:     workDir,
+		LogsDir: logsDir,
+		Env:     map[string]string{"DEVLOG_SESSION": "dev"},
+		Stdout:  &stdout,
+	}
+
+	err := r.Run("pre_up", []string{
+		`echo "$DEVLOG_HOOK $DEVLOG_SESSION $DEVLOG_LOGS_DIR"`,
+		"pwd > cwd.txt",
+	})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(logsDir, LogFile))
+	if err != nil {
+		t.Fatalf("hooks.log not written: %v", err)
+	}
+	log := string(data)
+	for _, want := range []string{
+		"pre_up: echo",
+		"pre_up dev " + logsDir + "\n",
+		"pre_up hook finished in",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("hooks.log missing %q:\n%s", want, log)
+		}
+	}
+
+	cwd, _ := os.ReadFile(filepath.Join(workDir, "cwd.txt"))
+	if strings.TrimSpace(string(cwd)) != workDir {
+		t.Errorf("hook cwd = %q, want %q", strings.TrimSpace(string(cwd)), workDir)
+	}
+	if !strings.Contains(stdout.String(), "Running pre_up hook: pwd > cwd.txt") {
+		t.Errorf("stdout = %q, want progress lines", stdout.String())
+	}
+}
+
+func TestRunner_RunStopsAtFirstFailure(t *testing.T) {
+	workDir := t.TempDir()
+	logsDir := t.TempDir()
+	r := Runner{Dir: workDir, LogsDir: logsDir}
+
+	err := r.Run("pre_up", []string{"echo boom >&2; exit 3", "touch ran.txt"})
+	if err == nil {
+		t.Fatal("Run() error = nil, want failure")
+	}
+	if !strings.Contains(err.Error(), "pre_up hook 'echo boom >&2; exit 3' failed: exit status 3") {
+		t.Errorf("Run() error = %q", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "ran.txt")); err == nil {
+		t.Error("second hook ran after the first one failed")
+	}
+
+	data, _ := os.ReadFile(filepath.Join(logsDir, LogFile))
+	if !strings.Contains(string(data), "boom\n") || !strings.Contains(string(data), "pre_up hook failed after") {
+		t.Errorf("hooks.log = %q, want stderr and failure footer", data)
+	}
+}
+
+func TestRunner_RunWithoutCommands(t *testing.T) {
+	logsDir := filepath.Join(t.TempDir(), "run")
+	if err := (Runner{LogsDir: logsDir}).Run("post_down", nil); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if _, err := os.Stat(logsDir); !os.IsNotExist(err) {
+		t.Error("Run() with no commands created the logs directory")
+	}
+}
@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/jellydn/devlog/internal/natmsg"
+)
+
+// Browser log formats.
+const (
+	FormatText   = "text"   // [TIMESTAMP] [LEVEL] [URL] source:line:col: message (default)
+	FormatJSONL  = "jsonl"  // one natmsg.Message JSON object per line
+	FormatLogfmt = "logfmt" // time=... level=... msg="..."
+)
+
+// Formats lists the accepted format names.
+var Formats = []string{FormatText, FormatJSONL, FormatLogfmt}
+
+// FormatOptions selects how Log writes messages.
+type FormatOptions struct {
+	Format   string // one of Formats; "" means FormatText
+	Template string // text/template for FormatText lines, executed with the *natmsg.Message (optional)
+}
+
+// templateFuncs are available in FormatOptions.Template.
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// formatter turns a message into one log line, newline included.
+type formatter func(msg *natmsg.Message) (string, error)
+
+// newFormatter checks opts and returns the formatter for them.
+func newFormatter(opts FormatOptions) (formatter, error) {
+	switch opts.Format {
+	case "", FormatText:
+		if opts.Template == "" {
+			return formatText, nil
+		}
+		tmpl, err := ParseTemplate(opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("template %w", err)
+		}
+		return func(msg *natmsg.Message) (string, error) {
+			var b strings.Builder
+			if err := tmpl.Execute(&b, msg); err != nil {
+				return "", fmt.Errorf("failed to format log line: %w", err)
+			}
+			return b.String() + "\n", nil
+		}, nil
+	case FormatJSONL, FormatLogfmt:
+		if opts.Template != "" {
+			return nil, fmt.Errorf("template requires the %s format", FormatText)
+		}
+		if opts.Format == FormatJSONL {
+			return formatJSONL, nil
+		}
+		return formatLogfmt, nil
+	default:
+		return nil, fmt.Errorf("unknown format '%s' (want one of %s)", opts.Format, strings.Join(Formats, ", "))
+	}
+}
+
+// ParseTemplate parses a FormatOptions.Template. Errors read as what the
+// template must be, e.g. "must be a single line".
+func ParseTemplate(text string) (*template.Template, error) {
+	if strings.ContainsAny(text, "\r\n") {
+		return nil, fmt.Errorf("must be a single line")
+	}
+	tmpl, err := template.New("browser").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("must be a valid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// ValidateFormat reports whether opts can be used with SetFormat.
+func ValidateFormat(opts FormatOptions) error {
+	_, err := newFormatter(opts)
+	return err
+}
+
+// formatText formats msg as: [TIMESTAMP] [LEVEL] [URL] source:line:col: message
+func formatText(msg *natmsg.Message) (string, error) {
+	timestamp := msg.Timestamp.Format("2006-01-02 15:04:05.000")
+
+	// Build log line
+	var logLine strings.Builder
+	logLine.WriteString("[")
+	logLine.WriteString(timestamp)
+	logLine.WriteString("] [")
+	logLine.WriteString(strings.ToUpper(msg.Level))
+	logLine.WriteString("]")
+
+	if msg.URL != "" {
+		logLine.WriteString(" [")
+		logLine.WriteString(msg.URL)
+		logLine.WriteString("]")
+	}
+
+	if msg.Source != "" {
+		logLine.WriteString(" ")
+		logLine.WriteString(msg.Source)
+		loc := formatLoc(msg.Line, msg.Column)
+		if loc != "" {
+			logLine.WriteString(loc)
+		}
+	}
+
+	logLine.WriteString(": ")
+	logLine.WriteString(msg.Message)
+	logLine.WriteString("\n")
+	return logLine.String(), nil
+}
+
+func formatLoc(line, column *int) string {
+	if line != nil && *line > 0 {
+		if column != nil && *column > 0 {
+			return fmt.Sprintf(":%d:%d", *line, *column)
+		}
+		return fmt.Sprintf(":%d", *line)
+	}
+	return ""
+}
+
+// formatJSONL writes msg with all its fields as a JSON object.
+func formatJSONL(msg *natmsg.Message) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(msg); err != nil {
+		return "", fmt.Errorf("failed to format log line: %w", err)
+	}
+	return b.String(), nil
+}
+
+// formatLogfmt writes msg as key=value pairs, quoting values when needed.
+func formatLogfmt(msg *natmsg.Message) (string, error) {
+	var b strings.Builder
+	pair := func(key, value string) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(value))
+	}
+	pair("time", msg.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"))
+	pair("level", msg.Level)
+	if msg.Type != "" {
+		pair("type", msg.Type)
+	}
+	if msg.URL != "" {
+		pair("url", msg.URL)
+	}
+	if msg.Source != "" {
+		pair("source", msg.Source)
+	}
+	if msg.Line != nil {
+		pair("line", strconv.Itoa(*msg.Line))
+	}
+	if msg.Column != nil {
+		pair("column", strconv.Itoa(*msg.Column))
+	}
+	pair("msg", msg.Message)
+	b.WriteByte('\n')
+	return b.String(), nil
+}
+
+// logfmtValue quotes v when it is empty or has spaces, quotes, '=' or
+// control characters.
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\\") || strings.IndexFunc(v, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// Command-line flags that pass FormatOptions to devlog-host.
+const (
+	FormatFlag   = "--format"
+	TemplateFlag = "--template"
+)
+
+// Args returns the flags for opts, to be read back with ParseArgs.
+func Args(opts FormatOptions) []string {
+	var args []string
+	if opts.Format != "" && opts.Format != FormatText {
+		args = append(args, FormatFlag, opts.Format)
+	}
+	if opts.Template != "" {
+		args = append(args, TemplateFlag, opts.Template)
+	}
+	return args
+}
+
+// ParseArgs takes the format flags out of args. It returns the other
+// arguments in order.
+func ParseArgs(args []string) (FormatOptions, []string, error) {
+	var opts FormatOptions
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case FormatFlag, TemplateFlag:
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("%s requires a value", args[i])
+			}
+			if args[i] == FormatFlag {
+				opts.Format = args[i+1]
+			} else {
+				opts.Template = args[i+1]
+			}
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return opts, rest, nil
+}
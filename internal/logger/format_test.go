@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jellydn/devlog/internal/natmsg"
+)
+
+func formatTestMessage() *natmsg.Message {
+	line, column := 42, 7
+	return &natmsg.Message{
+		Type:      "console",
+		Level:     "error",
+		Message:   "request failed\n  at fetch (app.js:42)",
+		URL:       "http://localhost:3000/search?q=[a]",
+		Timestamp: natmsg.Timestamp{Time: time.Date(2026, 2, 23, 12, 0, 0, 500e6, time.UTC)},
+		Source:    "app.js",
+		Line:      &line,
+		Column:    &column,
+	}
+}
+
+func TestLog_JSONL(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "browser.jsonl")
+	logger, err := New(logPath, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := logger.SetFormat(FormatOptions{Format: FormatJSONL}); err != nil {
+		t.Fatalf("SetFormat() error = %v", err)
+	}
+	msg := formatTestMessage()
+	if err := logger.Log(msg); err != nil {
+		t.Fatalf("failed to log message: %v", err)
+	}
+	logger.Close()
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if strings.Count(string(content), "\n") != 1 {
+		t.Fatalf("log = %q, want one line", content)
+	}
+	var got natmsg.Message
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if !got.Timestamp.Equal(msg.Timestamp.Time) {
+		t.Errorf("timestamp = %v, want %v", got.Timestamp, msg.Timestamp)
+	}
+	got.Timestamp = msg.Timestamp
+	if !reflect.DeepEqual(&got, msg) {
+		t.Errorf("decoded message = %+v, want %+v", got, *msg)
+	}
+}
+
+func TestFormatLogfmt(t *testing.T) {
+	got, err := formatLogfmt(formatTestMessage())
+	if err != nil {
+		t.Fatalf("formatLogfmt() error = %v", err)
+	}
+	want := `time=2026-02-23T12:00:00.500Z level=error type=console url="http://localhost:3000/search?q=[a]" source=app.js line=42 column=7 msg="request failed\n  at fetch (app.js:42)"` + "\n"
+	if got != want {
+		t.Errorf("formatLogfmt() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatTemplate(t *testing.T) {
+	format, err := newFormatter(FormatOptions{Template: `{{.Timestamp.Format "15:04:05"}} {{upper .Level}} {{.Source}}:{{.Line}} {{json .Message}}`})
+	if err != nil {
+		t.Fatalf("newFormatter() error = %v", err)
+	}
+	got, err := format(formatTestMessage())
+	if err != nil {
+		t.Fatalf("format() error = %v", err)
+	}
+	if want := `12:00:00 ERROR app.js:42 "request failed\n  at fetch (app.js:42)"` + "\n"; got != want {
+		t.Errorf("format() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		opts FormatOptions
+		want string
+	}{
+		{FormatOptions{Format: "xml"}, "unknown format 'xml' (want one of text, jsonl, logfmt)"},
+		{FormatOptions{Format: FormatJSONL, Template: "{{.Message}}"}, "template requires the text format"},
+		{FormatOptions{Template: "{{.Message"}, "template must be a valid template"},
+		{FormatOptions{Template: "{{.Level}}\n{{.Message}}"}, "template must be a single line"},
+	}
+	for _, tt := range tests {
+		if err := ValidateFormat(tt.opts); err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("ValidateFormat(%+v) = %v, want %q", tt.opts, err, tt.want)
+		}
+	}
+	for _, format := range append(Formats, "") {
+		if err := ValidateFormat(FormatOptions{Format: format}); err != nil {
+			t.Errorf("ValidateFormat(%q) = %v", format, err)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	opts := FormatOptions{Format: FormatText, Template: "{{.Message}}"}
+	got, rest, err := ParseArgs(append(Args(opts), "/logs/browser.log", "error"))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if got.Template != opts.Template || strings.Join(rest, " ") != "/logs/browser.log error" {
+		t.Errorf("ParseArgs() = %+v, %q", got, rest)
+	}
+	if Args(FormatOptions{Format: FormatText}) != nil {
+		t.Error("Args() for the default format should be empty")
+	}
+}
@@ -3,20 +3,23 @@ package logger
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 	"strings"
 	"sync"
 
+	"github.com/jellydn/devlog/internal/logrotate"
 	"github.com/jellydn/devlog/internal/natmsg"
+	"github.com/jellydn/devlog/internal/redact"
 )
 
 // Logger writes browser console logs to a file with level filtering
 type Logger struct {
-	file    *os.File
-	mu      sync.Mutex
-	levels  map[string]bool
-	logPath string
+	file     *logrotate.File
+	mu       sync.Mutex
+	levels   map[string]bool
+	logPath  string
+	redactor *redact.Redactor
+	format   formatter
 }
 
 // New creates a new logger that writes to the specified file.
@@ -24,16 +27,10 @@ type Logger struct {
 // levels is a list of log levels to capture (e.g., ["log", "error", "warn"]).
 // If empty, all levels are captured.
 func New(logPath string, levels []string) (*Logger, error) {
-	// Create log directory if needed
-	dir := filepath.Dir(logPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %w", err)
-	}
-
-	// Open log file (create or append)
-	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	// Open log file (create or append), creating its directory if needed
+	file, err := logrotate.OpenFile(logPath, logrotate.FileOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %w", err)
+		return nil, err
 	}
 
 	// Build level filter map
@@ -46,6 +43,7 @@ func New(logPath string, levels []string) (*Logger, error) {
 		file:    file,
 		levels:  levelMap,
 		logPath: logPath,
+		format:  formatText,
 	}, nil
 }
 
@@ -60,6 +58,25 @@ func (l *Logger) Close() error {
 	return nil
 }
 
+// SetRedactor masks secrets in every line logged from now on; nil turns
+// redaction off.
+func (l *Logger) SetRedactor(r *redact.Redactor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.redactor = r
+}
+
+// SetRotation rotates the log file by size from now on (see logrotate.File).
+func (l *Logger) SetRotation(opts logrotate.FileOptions) {
+	l.file.SetOptions(opts)
+}
+
+// OnRotateError sets a function that is told when rotating the log file
+// fails; logging carries on (see logrotate.File.OnError).
+func (l *Logger) OnRotateError(report func(error)) {
+	l.file.OnError(report)
+}
+
 // ShouldLog returns true if the given level should be logged
 func (l *Logger) ShouldLog(level string) bool {
 	// If no levels specified, log everything
@@ -69,8 +86,20 @@ func (l *Logger) ShouldLog(level string) bool {
 	return l.levels[strings.ToLower(level)]
 }
 
-// Log writes a message to the log file if it passes the level filter.
-// The message is formatted as: [TIMESTAMP] [LEVEL] [URL] message
+// SetFormat changes how messages are written (FormatText by default).
+func (l *Logger) SetFormat(opts FormatOptions) error {
+	format, err := newFormatter(opts)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.format = format
+	return nil
+}
+
+// Log writes a message to the log file if it passes the level filter, in
+// the logger's format, with secrets masked when a redactor is set.
 func (l *Logger) Log(msg *natmsg.Message) error {
 	if !l.ShouldLog(msg.Level) {
 		return nil
@@ -79,52 +108,23 @@ func (l *Logger) Log(msg *natmsg.Message) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	timestamp := msg.Timestamp.Format("2006-01-02 15:04:05.000")
-
-	// Build log line
-	var logLine strings.Builder
-	logLine.WriteString("[")
-	logLine.WriteString(timestamp)
-	logLine.WriteString("] [")
-	logLine.WriteString(strings.ToUpper(msg.Level))
-	logLine.WriteString("]")
-
-	if msg.URL != "" {
-		logLine.WriteString(" [")
-		logLine.WriteString(msg.URL)
-		logLine.WriteString("]")
-	}
+	// Redact the fields rather than the line, so structured formats stay valid
+	redacted := *msg
+	redacted.Message = l.redactor.Redact(msg.Message)
+	redacted.URL = l.redactor.Redact(msg.URL)
+	redacted.Source = l.redactor.Redact(msg.Source)
 
-	if msg.Source != "" {
-		logLine.WriteString(" ")
-		logLine.WriteString(msg.Source)
-		loc := formatLoc(msg.Line, msg.Column)
-		if loc != "" {
-			logLine.WriteString(loc)
-		}
+	line, err := l.format(&redacted)
+	if err != nil {
+		return err
 	}
-
-	logLine.WriteString(": ")
-	logLine.WriteString(msg.Message)
-	logLine.WriteString("\n")
-
-	if _, err := l.file.WriteString(logLine.String()); err != nil {
+	if _, err := io.WriteString(l.file, line); err != nil {
 		return fmt.Errorf("failed to write log: %w", err)
 	}
 
 	return nil
 }
 
-func formatLoc(line, column *int) string {
-	if line != nil && *line > 0 {
-		if column != nil && *column > 0 {
-			return fmt.Sprintf(":%d:%d", *line, *column)
-		}
-		return fmt.Sprintf(":%d", *line)
-	}
-	return ""
-}
-
 // LogPath returns the path to the log file
 func (l *Logger) LogPath() string {
 	return l.logPath
@@ -0,0 +1,283 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// DefaultMaxFiles is the number of rotated files kept when FileOptions.MaxFiles is 0.
+const DefaultMaxFiles = 5
+
+// FileOptions controls size-based rotation of a single log file.
+type FileOptions struct {
+	MaxSize  int64 // rotate before a write takes the file past this many bytes; 0 disables rotation
+	MaxFiles int   // rotated files kept (path.1 is the newest); DefaultMaxFiles when 0
+	Compress bool  // gzip rotated files (path.1.gz, ...)
+}
+
+// File is an append-only log file that rotates itself by size: path is
+// renamed to path.1, path.1 to path.2 and so on, and the oldest is removed.
+// It assumes it is the only writer of path.
+//
+// A failed rotation doesn't stop the log: File goes on appending to path and
+// tries again on a later write. Rotated files are compressed in the
+// background, so a write never waits for gzip.
+type File struct {
+	mu       sync.Mutex
+	path     string
+	opts     FileOptions
+	f        *os.File
+	size     int64
+	closed   bool
+	failing  bool           // the last rotation failed
+	onError  func(error)    // told about rotation and compression failures
+	compress sync.WaitGroup // running compression of path.1
+}
+
+// OpenFile opens path for appending, creating it and its directory.
+func OpenFile(path string, opts FileOptions) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file := &File{path: path, opts: opts}
+	if err := file.open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.f, f.size = file, info.Size()
+	return nil
+}
+
+// SetOptions changes the rotation options for later writes.
+func (f *File) SetOptions(opts FileOptions) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opts = opts
+}
+
+// OnError sets a function that is told when rotating or compressing fails.
+// Writes carry on regardless; a rotation that keeps failing is reported
+// once, until one succeeds again.
+func (f *File) OnError(report func(error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onError = report
+}
+
+// Write appends p, rotating first when p would take the file past MaxSize.
+// A write is never split across files.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	var failed error
+	if f.f != nil && f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize {
+		err := f.rotate()
+		if err != nil && !f.failing {
+			failed = err
+		}
+		f.failing = err != nil
+	}
+	n, err := f.write(p)
+	report := f.onError
+	f.mu.Unlock()
+
+	if failed != nil && report != nil {
+		report(failed)
+	}
+	return n, err
+}
+
+// write appends p to the current file, reopening it when a failed rotation
+// left it closed.
+func (f *File) write(p []byte) (int, error) {
+	if f.f == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file, after a compression still running.
+func (f *File) Close() error {
+	f.compress.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
+
+// rotate shifts the rotated files up by one, moves the current file to
+// path.1 and starts a new, empty file, then compresses path.1 in the
+// background when asked to. When a rename fails, path is reopened as it is.
+func (f *File) rotate() error {
+	// The previous path.1 must be compressed before it is shifted
+	f.compress.Wait()
+
+	err := f.f.Close()
+	f.f = nil
+	if err == nil {
+		err = f.shift()
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to rotate log file: %w", err)
+		if openErr := f.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	if f.opts.Compress {
+		rotated, report := f.rotated(1), f.onError
+		f.compress.Add(1)
+		go func() {
+			err := compressFile(rotated)
+			// Done first: report may write to f, and the next rotation waits
+			f.compress.Done()
+			if err != nil && report != nil {
+				report(fmt.Errorf("failed to compress rotated log file: %w", err))
+			}
+		}()
+	}
+	return nil
+}
+
+// shift renames the rotated files and path up by one, removing the oldest.
+func (f *File) shift() error {
+	maxFiles := f.opts.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	ext := ""
+	if f.opts.Compress {
+		ext = ".gz"
+	}
+	os.Remove(f.rotated(maxFiles) + ext)
+	for i := maxFiles - 1; i >= 1; i-- {
+		if err := os.Rename(f.rotated(i)+ext, f.rotated(i+1)+ext); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(f.path, f.rotated(1))
+}
+
+// rotated returns the path of the i-th rotated file, without a .gz suffix.
+func (f *File) rotated(i int) string {
+	return f.path + "." + strconv.Itoa(i)
+}
+
+// compressFile replaces path with path.gz.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// Command-line flags that pass FileOptions to `devlog pipe` and devlog-host.
+const (
+	MaxFileSizeFlag = "--max-file-size"
+	MaxFilesFlag    = "--max-files"
+	CompressFlag    = "--compress"
+)
+
+// Args returns the flags for opts, to be read back with ParseArgs. It is
+// empty when rotation is off.
+func Args(opts FileOptions) []string {
+	if opts.MaxSize <= 0 {
+		return nil
+	}
+	args := []string{MaxFileSizeFlag, strconv.FormatInt(opts.MaxSize, 10)}
+	if opts.MaxFiles > 0 {
+		args = append(args, MaxFilesFlag, strconv.Itoa(opts.MaxFiles))
+	}
+	if opts.Compress {
+		args = append(args, CompressFlag)
+	}
+	return args
+}
+
+// ParseArgs takes the rotation flags out of args. It returns the other
+// arguments in order.
+func ParseArgs(args []string) (FileOptions, []string, error) {
+	var opts FileOptions
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case CompressFlag:
+			opts.Compress = true
+		case MaxFileSizeFlag, MaxFilesFlag:
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("%s requires a value", args[i])
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n < 0 {
+				return opts, nil, fmt.Errorf("%s must be a non-negative number, got '%s'", args[i], args[i+1])
+			}
+			if args[i] == MaxFileSizeFlag {
+				opts.MaxSize = n
+			} else {
+				opts.MaxFiles = int(n)
+			}
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return opts, rest, nil
+}
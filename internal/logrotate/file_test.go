@@ -0,0 +1,185 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestFile_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenFile(path, FileOptions{MaxSize: 10, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	for _, line := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	f.Close()
+
+	// old\nline1\n fits in 10 bytes; each later line starts a new file
+	want := map[string]string{"api.log": "line4\n", "api.log.1": "line3\n", "api.log.2": "line2\n"}
+	for name, content := range want {
+		if got := readFile(t, filepath.Join(filepath.Dir(path), name)); got != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("api.log.3 exists, want at most max_files rotated files")
+	}
+}
+
+func TestFile_OversizedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	f, err := OpenFile(path, FileOptions{MaxSize: 4})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	f.Write([]byte("a long line\n"))
+	f.Close()
+	if got := readFile(t, path); got != "a long line\n" {
+		t.Errorf("api.log = %q, want the whole write in the empty file", got)
+	}
+}
+
+func TestFile_Compress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	f, err := OpenFile(path, FileOptions{MaxSize: 8, Compress: true})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	f.Write([]byte("first\n"))
+	f.Write([]byte("second\n"))
+	f.Write([]byte("third\n"))
+	f.Close()
+
+	for name, content := range map[string]string{"api.log.1.gz": "second\n", "api.log.2.gz": "first\n"} {
+		gz, err := os.Open(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		zr, err := gzip.NewReader(gz)
+		if err != nil {
+			t.Fatalf("%s is not gzip: %v", name, err)
+		}
+		data, _ := io.ReadAll(zr)
+		gz.Close()
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("uncompressed api.log.1 was left behind")
+	}
+}
+
+func TestFile_RotateFailureKeepsLogging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	// A non-empty directory in the way of api.log.1 makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenFile(path, FileOptions{MaxSize: 8, MaxFiles: 1})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	var reported []error
+	f.OnError(func(err error) { reported = append(reported, err) })
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%q) error = %v, want the write to go on after a failed rotation", line, err)
+		}
+	}
+
+	if len(reported) != 1 || !strings.Contains(reported[0].Error(), "failed to rotate log file") {
+		t.Errorf("reported errors = %v, want one rotation failure", reported)
+	}
+	if got := readFile(t, path); got != "first\nsecond\nthird\n" {
+		t.Errorf("api.log = %q, want every write appended", got)
+	}
+
+	// Rotation works again once the way is clear, and is reported again
+	// only when it fails anew
+	os.RemoveAll(path + ".1")
+	f.Write([]byte("fourth\n"))
+	f.Close()
+	if got := readFile(t, path); got != "fourth\n" {
+		t.Errorf("api.log after recovery = %q, want %q", got, "fourth\n")
+	}
+	if len(reported) != 1 {
+		t.Errorf("reported errors = %v, want no more", reported)
+	}
+}
+
+func TestFile_CompressFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	// A non-empty directory in the way of api.log.1.gz makes compression fail
+	if err := os.MkdirAll(filepath.Join(path+".1.gz", "blocker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenFile(path, FileOptions{MaxSize: 8, MaxFiles: 1, Compress: true})
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	reported := make(chan error, 1)
+	f.OnError(func(err error) { reported <- err })
+	f.Write([]byte("first\n"))
+	if _, err := f.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	f.Close()
+
+	select {
+	case err := <-reported:
+		if !strings.Contains(err.Error(), "failed to compress") {
+			t.Errorf("reported error = %v, want compression failure", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("compression failure was not reported")
+	}
+	if got := readFile(t, path); got != "second\n" {
+		t.Errorf("api.log = %q, want %q", got, "second\n")
+	}
+	if got := readFile(t, path+".1"); got != "first\n" {
+		t.Errorf("api.log.1 = %q, want the uncompressed file kept", got)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	opts := FileOptions{MaxSize: 1 << 20, MaxFiles: 3, Compress: true}
+	args := append([]string{"api.log"}, Args(opts)...)
+	got, rest, err := ParseArgs(args)
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if got != opts || strings.Join(rest, " ") != "api.log" {
+		t.Errorf("ParseArgs() = %+v, %q; want %+v, [api.log]", got, rest, opts)
+	}
+	if Args(FileOptions{MaxFiles: 3}) != nil {
+		t.Error("Args() without MaxSize should be empty")
+	}
+	if _, _, err := ParseArgs([]string{MaxFileSizeFlag, "10MB"}); err == nil {
+		t.Error("ParseArgs() with a non-numeric size: want error")
+	}
+}
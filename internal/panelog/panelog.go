@@ -0,0 +1,186 @@
+// Package panelog processes pane output on its way to the pane's log file
+// (`devlog pipe`, run by tmux pipe-pane).
+package panelog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/redact"
+)
+
+// Options controls how pane output is written to the log.
+type Options struct {
+	Timestamps string           // config.TimestampsRFC3339, config.TimestampsRelative or none
+	Start      time.Time        // run start, for relative timestamps
+	Sanitize   bool             // strip escape sequences and apply \r and \b redraws
+	Raw        io.Writer        // receives the unprocessed output too (optional)
+	Redactor   *redact.Redactor // masks secrets in the log and the raw copy (optional)
+
+	now func() time.Time // replaced in tests
+}
+
+// Copy copies pane output from src to dst until src is closed, processed as
+// opts ask. Plain output is written as it arrives, so a partial line (e.g. a
+// prompt) is not held back; its prefix carries the time the line started.
+// Sanitized or redacted output is written a line at a time, so a secret
+// split across reads is still matched.
+func Copy(dst io.Writer, src io.Reader, opts Options) error {
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+	// Writers that hold back a partial line, flushed in order at EOF
+	var flushers []flusher
+	var w io.Writer = &prefixWriter{w: dst, prefix: opts.prefix, lineStart: true}
+	if opts.Redactor != nil {
+		redacted := &redactWriter{w: w, redactor: opts.Redactor}
+		w = redacted
+		flushers = append(flushers, redacted)
+	}
+	if opts.Sanitize {
+		sanitizer := NewSanitizer(w)
+		w = sanitizer
+		flushers = append([]flusher{sanitizer}, flushers...)
+	}
+	raw := opts.Raw
+	if raw != nil && opts.Redactor != nil {
+		redactedRaw := &redactWriter{w: raw, redactor: opts.Redactor}
+		raw = redactedRaw
+		flushers = append(flushers, redactedRaw)
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			if raw != nil {
+				if _, err := raw.Write(buf[:n]); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			for _, f := range flushers {
+				if err := f.Flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// prefix returns the timestamp written at the start of a line.
+func (o Options) prefix() string {
+	now := o.now()
+	switch o.Timestamps {
+	case config.TimestampsRFC3339:
+		return now.Format("2006-01-02T15:04:05.000Z07:00") + " "
+	case config.TimestampsRelative:
+		return fmt.Sprintf("+%.3fs ", now.Sub(o.Start).Seconds())
+	default:
+		return ""
+	}
+}
+
+// timestampPrefixes match the prefixes prefix() writes, by format.
+var timestampPrefixes = map[string]*regexp.Regexp{
+	config.TimestampsRFC3339:  regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}(Z|[+-]\d{2}:\d{2}) `),
+	config.TimestampsRelative: regexp.MustCompile(`^\+-?\d+\.\d{3}s `),
+}
+
+// TimestampPrefix returns a regular expression matching the timestamp that
+// Copy writes at the start of every line in the given format, or nil when
+// the format adds none.
+func TimestampPrefix(timestamps string) *regexp.Regexp {
+	return timestampPrefixes[timestamps]
+}
+
+// prefixWriter writes prefix() before the first byte of every line.
+type prefixWriter struct {
+	w         io.Writer
+	prefix    func() string
+	lineStart bool
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	written := 0
+	for len(data) > 0 {
+		if p.lineStart {
+			if prefix := p.prefix(); prefix != "" {
+				if _, err := io.WriteString(p.w, prefix); err != nil {
+					return written, err
+				}
+			}
+		}
+		end := len(data)
+		for i, b := range data {
+			if b == '\n' {
+				end = i + 1
+				break
+			}
+		}
+		n, err := p.w.Write(data[:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p.lineStart = data[end-1] == '\n'
+		data = data[end:]
+	}
+	return written, nil
+}
+
+// maxRedactLine bounds the bytes a redactWriter holds waiting for a newline.
+const maxRedactLine = 64 * 1024
+
+// redactWriter passes whole lines through a Redactor.
+type redactWriter struct {
+	w        io.Writer
+	redactor *redact.Redactor
+	line     []byte
+}
+
+func (r *redactWriter) Write(data []byte) (int, error) {
+	n := len(data)
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			r.line = append(r.line, data...)
+			if len(r.line) >= maxRedactLine {
+				return n, r.Flush()
+			}
+			return n, nil
+		}
+		r.line = append(r.line, data[:i+1]...)
+		data = data[i+1:]
+		if err := r.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// Flush writes the buffered (partial) line.
+func (r *redactWriter) Flush() error {
+	if len(r.line) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(r.w, r.redactor.Redact(string(r.line)))
+	r.line = r.line[:0]
+	return err
+}
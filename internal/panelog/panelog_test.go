@@ -0,0 +1,113 @@
+package panelog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/redact"
+)
+
+// chunkReader returns its chunks one Read at a time, like a pipe.
+type chunkReader struct{ chunks []string }
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func TestCopy_Timestamps(t *testing.T) {
+	start := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tick := start
+	now := func() time.Time {
+		tick = tick.Add(1500 * time.Millisecond)
+		return tick
+	}
+	src := func() io.Reader { return &chunkReader{chunks: []string{"one\ntw", "o\n", "$ "}} }
+
+	tests := []struct {
+		timestamps string
+		want       string
+	}{
+		{"", "one\ntwo\n$ "},
+		{config.TimestampsNone, "one\ntwo\n$ "},
+		{config.TimestampsRelative, "+1.500s one\n+3.000s two\n+4.500s $ "},
+		{config.TimestampsRFC3339, "2026-01-02T15:04:06.500Z one\n2026-01-02T15:04:08.000Z two\n2026-01-02T15:04:09.500Z $ "},
+	}
+	for _, tt := range tests {
+		tick = start
+		var dst bytes.Buffer
+		if err := Copy(&dst, src(), Options{Timestamps: tt.timestamps, Start: start, now: now}); err != nil {
+			t.Fatalf("Copy() error = %v", err)
+		}
+		if dst.String() != tt.want {
+			t.Errorf("Copy() with timestamps %q = %q, want %q", tt.timestamps, dst.String(), tt.want)
+		}
+
+		// TimestampPrefix takes the prefixes off again
+		lines := strings.Split(dst.String(), "\n")
+		if prefix := TimestampPrefix(tt.timestamps); prefix != nil {
+			for i, line := range lines {
+				lines[i] = prefix.ReplaceAllString(line, "")
+			}
+		}
+		if got := strings.Join(lines, "\n"); got != "one\ntwo\n$ " {
+			t.Errorf("lines without TimestampPrefix(%q) = %q, want the output", tt.timestamps, got)
+		}
+	}
+
+	if prefix := TimestampPrefix(config.TimestampsRFC3339); !prefix.MatchString("2026-01-02T16:04:05.000+01:00 one") {
+		t.Errorf("TimestampPrefix(rfc3339) does not match a time with a zone offset")
+	}
+}
+
+func TestCopy_LargeInput(t *testing.T) {
+	line := strings.Repeat("x", 100*1024) + "\n"
+	var dst bytes.Buffer
+	if err := Copy(&dst, strings.NewReader(line+line), Options{}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if dst.String() != line+line {
+		t.Errorf("Copy() changed a %d byte input", len(line)*2)
+	}
+}
+
+func TestCopy_SanitizeRaw(t *testing.T) {
+	in := []string{"\x1b[32mready\x1b", "[0m\n 10%\r100%\n$ "}
+	var dst, raw bytes.Buffer
+	opts := Options{Timestamps: config.TimestampsRelative, Sanitize: true, Raw: &raw, now: func() time.Time { return time.Unix(1, 0) }, Start: time.Unix(0, 0)}
+	if err := Copy(&dst, &chunkReader{chunks: in}, opts); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if want := "+1.000s ready\n+1.000s 100%\n+1.000s $"; dst.String() != want {
+		t.Errorf("Copy() = %q, want %q", dst.String(), want)
+	}
+	if raw.String() != strings.Join(in, "") {
+		t.Errorf("raw copy = %q, want the unprocessed input", raw.String())
+	}
+}
+
+func TestCopy_Redact(t *testing.T) {
+	redactor, err := redact.New([]string{"url-credentials"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := []string{"DATABASE_URL=postgres://app:s3", "cr3t@db/app\n$ \x1b[1mpostgres://u:pw@h\x1b[0m"}
+	var dst, raw bytes.Buffer
+	if err := Copy(&dst, &chunkReader{chunks: in}, Options{Sanitize: true, Raw: &raw, Redactor: redactor}); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if want := "DATABASE_URL=postgres://app:[REDACTED]@db/app\n$ postgres://u:[REDACTED]@h"; dst.String() != want {
+		t.Errorf("Copy() = %q, want %q", dst.String(), want)
+	}
+	if want := "DATABASE_URL=postgres://app:[REDACTED]@db/app\n$ \x1b[1mpostgres://u:[REDACTED]@h\x1b[0m"; raw.String() != want {
+		t.Errorf("raw copy = %q, want %q", raw.String(), want)
+	}
+}
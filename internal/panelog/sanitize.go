@@ -0,0 +1,198 @@
+package panelog
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineRunes bounds the line a Sanitizer holds; longer lines are split.
+const maxLineRunes = 64 * 1024
+
+type sanitizeState int
+
+const (
+	stateText            sanitizeState = iota
+	stateEsc                           // after ESC
+	stateEscIntermediate               // ESC followed by intermediate bytes, e.g. ESC ( B
+	stateCSI                           // ESC [ ... final byte
+	stateString                        // OSC, DCS, PM, APC and SOS, up to BEL or ESC \
+	stateStringEsc                     // ESC inside a string, maybe its terminator
+)
+
+// Sanitizer turns raw terminal output into plain text lines: escape
+// sequences (colors, cursor keys, titles) are dropped, and carriage returns,
+// backspaces and erase-in-line are applied to the current line the way a
+// terminal would, so a progress bar redrawn with \r ends up as its last
+// state. Sequences may be split across writes. Lines are passed on to the
+// underlying writer when they end; call Flush for the last partial line.
+type Sanitizer struct {
+	w       io.Writer
+	state   sanitizeState
+	params  []byte // CSI parameter bytes
+	pending []byte // incomplete UTF-8 sequence from the previous write
+	line    []rune
+	col     int
+}
+
+// NewSanitizer returns a Sanitizer writing clean lines to w.
+func NewSanitizer(w io.Writer) *Sanitizer {
+	return &Sanitizer{w: w}
+}
+
+// Write processes data; it always consumes all of it unless w fails.
+func (s *Sanitizer) Write(data []byte) (int, error) {
+	n := len(data)
+	if len(s.pending) > 0 {
+		data = append(s.pending, data...)
+		s.pending = nil
+	}
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch s.state {
+		case stateText:
+			if b >= utf8.RuneSelf {
+				if !utf8.FullRune(data[i:]) {
+					s.pending = append([]byte(nil), data[i:]...)
+					return n, nil
+				}
+				r, size := utf8.DecodeRune(data[i:])
+				s.put(r)
+				i += size - 1
+				continue
+			}
+			if err := s.control(b); err != nil {
+				return 0, err
+			}
+		case stateEsc:
+			switch {
+			case b == '[':
+				s.state, s.params = stateCSI, s.params[:0]
+			case b == ']' || b == 'P' || b == 'X' || b == '^' || b == '_':
+				s.state = stateString
+			case b >= 0x20 && b <= 0x2f:
+				s.state = stateEscIntermediate
+			default:
+				s.state = stateText
+			}
+		case stateEscIntermediate:
+			if b < 0x20 || b > 0x2f {
+				s.state = stateText
+			}
+		case stateCSI:
+			switch {
+			case b >= 0x30 && b <= 0x3f:
+				if len(s.params) < 32 {
+					s.params = append(s.params, b)
+				}
+			case b >= 0x40 && b <= 0x7e:
+				s.csi(b)
+				s.state = stateText
+			case b < 0x20 || b > 0x7e:
+				// Not a valid sequence: drop it and read b as text
+				s.state = stateText
+				i--
+			}
+		case stateString:
+			if b == 0x07 {
+				s.state = stateText
+			} else if b == 0x1b {
+				s.state = stateStringEsc
+			}
+		case stateStringEsc:
+			if b == '\\' {
+				s.state = stateText
+			} else {
+				s.state = stateString
+			}
+		}
+	}
+	return n, nil
+}
+
+// control handles an ASCII byte in text.
+func (s *Sanitizer) control(b byte) error {
+	switch {
+	case b == 0x1b:
+		s.state = stateEsc
+	case b == '\n':
+		return s.emit("\n")
+	case b == '\r':
+		s.col = 0
+	case b == '\b':
+		if s.col > 0 {
+			s.col--
+		}
+	case b == '\t' || (b >= 0x20 && b < 0x7f):
+		s.put(rune(b))
+		if len(s.line) >= maxLineRunes {
+			return s.emit("\n")
+		}
+	}
+	// Other control characters (BEL, NUL, DEL, ...) are dropped
+	return nil
+}
+
+// csi applies the cursor and erase sequences that change the current line;
+// everything else (colors, modes, moves to other lines) is dropped.
+func (s *Sanitizer) csi(final byte) {
+	n := 1
+	if v, err := strconv.Atoi(string(s.params)); err == nil && v > 0 {
+		n = min(v, maxLineRunes)
+	}
+	switch final {
+	case 'C': // cursor forward
+		s.col = min(s.col+n, maxLineRunes)
+	case 'D': // cursor back
+		s.col = max(s.col-n, 0)
+	case 'G': // cursor to column
+		s.col = n - 1
+	case 'K': // erase in line
+		switch string(s.params) {
+		case "", "0":
+			if s.col < len(s.line) {
+				s.line = s.line[:s.col]
+			}
+		case "1":
+			for i := 0; i <= s.col && i < len(s.line); i++ {
+				s.line[i] = ' '
+			}
+		case "2":
+			s.line = s.line[:0]
+		}
+	}
+}
+
+// put writes r at the cursor, overwriting what is there.
+func (s *Sanitizer) put(r rune) {
+	for len(s.line) < s.col {
+		s.line = append(s.line, ' ')
+	}
+	if s.col < len(s.line) {
+		s.line[s.col] = r
+	} else {
+		s.line = append(s.line, r)
+	}
+	s.col++
+}
+
+// emit writes the current line followed by end and starts a new line.
+func (s *Sanitizer) emit(end string) error {
+	line := strings.TrimRight(string(s.line), " ") + end
+	s.line, s.col = s.line[:0], 0
+	if line == "" {
+		return nil
+	}
+	_, err := io.WriteString(s.w, line)
+	return err
+}
+
+// Flush writes the current partial line, if any.
+func (s *Sanitizer) Flush() error {
+	if len(s.pending) > 0 {
+		s.put(utf8.RuneError)
+		s.pending = nil
+	}
+	return s.emit("")
+}
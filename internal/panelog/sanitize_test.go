@@ -0,0 +1,66 @@
+package panelog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSanitizer(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "hello\nworld\n", "hello\nworld\n"},
+		{"colors", "\x1b[32m✓\x1b[0m built in \x1b[1;33m120ms\x1b[m\n", "✓ built in 120ms\n"},
+		{"progress redraw", "[=   ] 10%\r[==  ] 50%\r[====] 100%\n", "[====] 100%\n"},
+		{"shorter redraw", "downloading 100 files\r\x1b[Kdone\n", "done\n"},
+		{"clear line", "waiting...\x1b[2K\rready\n", "ready\n"},
+		{"overwrite keeps tail", "abcdef\rXY\n", "XYcdef\n"},
+		{"backspace", "hellx\bo\n", "hello\n"},
+		{"crlf", "windows\r\n", "windows\n"},
+		{"cursor back", "100%\x1b[4D 50%\n", " 50%\n"},
+		{"column", "abc\x1b[1Gz\n", "zbc\n"},
+		{"title and hyperlink", "\x1b]0;vite\x07\x1b]8;;http://x\x1b\\link\x1b]8;;\x1b\\\n", "link\n"},
+		{"charset and modes", "\x1b(B\x1b[?25lok\x1b[?25h\x07\n", "ok\n"},
+		{"cursor up dropped", "line1\n\x1b[1Aline2\n", "line1\nline2\n"},
+		{"partial last line", "prompt> ", "prompt>"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		s := NewSanitizer(&out)
+		if _, err := s.Write([]byte(tt.in)); err != nil {
+			t.Fatalf("%s: Write() error = %v", tt.name, err)
+		}
+		if err := s.Flush(); err != nil {
+			t.Fatalf("%s: Flush() error = %v", tt.name, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, out.String(), tt.want)
+		}
+	}
+}
+
+func TestSanitizer_SplitWrites(t *testing.T) {
+	in := "\x1b[38;5;208mcafé ✓\x1b[0m 10%\r\x1b]0;title\x1b\\\x1b[Kdone 100%\n"
+	want := "done 100%\n"
+	// Split at every byte offset so escape sequences and UTF-8 characters
+	// are cut in every possible place
+	for i := 0; i <= len(in); i++ {
+		var out bytes.Buffer
+		s := NewSanitizer(&out)
+		s.Write([]byte(in[:i]))
+		s.Write([]byte(in[i:]))
+		s.Flush()
+		if out.String() != want {
+			t.Errorf("split at %d: got %q, want %q", i, out.String(), want)
+		}
+	}
+
+	var out bytes.Buffer
+	s := NewSanitizer(&out)
+	for _, b := range []byte("caf\xc3\xa9\n") {
+		s.Write([]byte{b})
+	}
+	if out.String() != "café\n" {
+		t.Errorf("byte-at-a-time UTF-8 = %q, want %q", out.String(), "café\n")
+	}
+}
@@ -0,0 +1,175 @@
+// Package probe implements readiness checks: a TCP port accepting
+// connections, an HTTP endpoint returning an expected status, or a pattern
+// appearing in a log file.
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Probe is a single readiness check.
+type Probe interface {
+	// Check returns nil when the target is ready.
+	Check(ctx context.Context) error
+	// String describes the probe for progress and error output.
+	String() string
+}
+
+// TCP is ready once Address accepts a connection.
+type TCP struct {
+	Address string
+}
+
+func (p TCP) Check(ctx context.Context) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", p.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (p TCP) String() string {
+	return "tcp " + p.Address
+}
+
+// HTTP is ready once a GET of URL returns Status, or any 2xx/3xx status when
+// Status is 0. Redirects are not followed.
+type HTTP struct {
+	URL    string
+	Status int
+}
+
+var httpClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func (p HTTP) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if p.Status != 0 {
+		if resp.StatusCode != p.Status {
+			return fmt.Errorf("got status %d, want %d", resp.StatusCode, p.Status)
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("got status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p HTTP) String() string {
+	if p.Status != 0 {
+		return fmt.Sprintf("http %s (status %d)", p.URL, p.Status)
+	}
+	return "http " + p.URL
+}
+
+// Log is ready once Pattern matches a line of Path written after Offset.
+// Offset lets callers ignore output from earlier runs in a reused log file.
+// When Path has been rotated since (it is shorter than Offset, or no longer
+// OffsetFile), it is all new and read from the start.
+// Prefix, when set, is removed from the start of each line before matching,
+// so an anchored Pattern works on logs with timestamped lines.
+type Log struct {
+	Path       string
+	Pattern    *regexp.Regexp
+	Offset     int64
+	OffsetFile os.FileInfo // the file Offset was measured in (optional)
+	Prefix     *regexp.Regexp
+}
+
+// ansiEscapeRegex matches CSI and OSC terminal escape sequences.
+var ansiEscapeRegex = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\))`)
+
+func (p Log) Check(ctx context.Context) error {
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	offset := p.Offset
+	if info.Size() < offset || (p.OffsetFile != nil && !os.SameFile(p.OffsetFile, info)) {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	// Pane logs are raw terminal output: drop escape sequences and treat \r as
+	// a line break so ^ and $ behave as they do in grep
+	data = ansiEscapeRegex.ReplaceAll(data, nil)
+	for _, line := range bytes.FieldsFunc(data, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if p.Prefix != nil {
+			if loc := p.Prefix.FindIndex(line); loc != nil && loc[0] == 0 {
+				line = line[loc[1]:]
+			}
+		}
+		if p.Pattern.Match(line) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no line matching /%s/ yet", p.Pattern)
+}
+
+func (p Log) String() string {
+	return fmt.Sprintf("log /%s/ in %s", p.Pattern, p.Path)
+}
+
+// Wait runs p every interval until it succeeds or timeout elapses. On timeout
+// the error includes the last check failure.
+func Wait(ctx context.Context, p Probe, timeout, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		// Bound each check so a hung connection cannot eat the whole timeout
+		checkCtx, cancelCheck := context.WithTimeout(ctx, max(interval, 2*time.Second))
+		lastErr = p.Check(checkCtx)
+		cancelCheck()
+		if lastErr == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%s not ready after %s: %w", p, timeout, lastErr)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
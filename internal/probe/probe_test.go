@@ -0,0 +1,153 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+
+	if err := (TCP{Address: addr}).Check(context.Background()); err != nil {
+		t.Errorf("Check() on open port failed: %v", err)
+	}
+
+	ln.Close()
+	if err := (TCP{Address: addr}).Check(context.Background()); err == nil {
+		t.Error("Check() on closed port succeeded, want error")
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	if err := (HTTP{URL: srv.URL}).Check(context.Background()); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Check() error = %v, want status 503 error", err)
+	}
+	if err := (HTTP{URL: srv.URL, Status: 503}).Check(context.Background()); err != nil {
+		t.Errorf("Check() with expected status 503 failed: %v", err)
+	}
+
+	status.Store(http.StatusNoContent)
+	if err := (HTTP{URL: srv.URL}).Check(context.Background()); err != nil {
+		t.Errorf("Check() on 204 failed: %v", err)
+	}
+	if err := (HTTP{URL: srv.URL, Status: 200}).Check(context.Background()); err == nil {
+		t.Error("Check() with expected status 200 on 204 succeeded, want error")
+	}
+}
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.log")
+	if err := os.WriteFile(path, []byte("ready to accept connections (old run)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+
+	p := Log{Path: path, Pattern: regexp.MustCompile(`^ready to accept connections$`), Offset: info.Size()}
+	if err := p.Check(context.Background()); err == nil {
+		t.Error("Check() matched output before Offset, want error")
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("starting\r\n\x1b[?2004l\rready to accept connections\r\n")
+	f.Close()
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("Check() failed after match was written: %v", err)
+	}
+}
+
+func TestLog_Rotated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	os.WriteFile(path, []byte("listening on :8080 (old run)\n"), 0644)
+	info, _ := os.Stat(path)
+	p := Log{Path: path, Pattern: regexp.MustCompile(`^listening on`), Offset: info.Size(), OffsetFile: info}
+
+	// Rotated: a new, shorter file
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("listening on\n"), 0644)
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("Check() on a file shorter than Offset failed: %v", err)
+	}
+
+	// Rotated again: a new file longer than Offset, matching at its start
+	os.Rename(path+".1", path+".2")
+	os.Rename(path, path+".1")
+	os.WriteFile(path, []byte("listening on :8080\n"+strings.Repeat("request\n", 10)), 0644)
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("Check() on a replaced file failed: %v", err)
+	}
+
+	// Without OffsetFile only the size tells a rotation
+	p.OffsetFile = nil
+	if err := p.Check(context.Background()); err == nil {
+		t.Error("Check() without OffsetFile matched before Offset, want error")
+	}
+}
+
+func TestLog_Prefix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	os.WriteFile(path, []byte("+0.120s starting\n+1.503s listening on :8080\n"), 0644)
+
+	p := Log{Path: path, Pattern: regexp.MustCompile(`^listening on`)}
+	if err := p.Check(context.Background()); err == nil {
+		t.Error("Check() matched an anchored pattern after a timestamp, want error")
+	}
+	p.Prefix = regexp.MustCompile(`^\+\d+\.\d{3}s `)
+	if err := p.Check(context.Background()); err != nil {
+		t.Errorf("Check() with Prefix failed: %v", err)
+	}
+}
+
+func TestWait_Timeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	start := time.Now()
+	err = Wait(context.Background(), TCP{Address: addr}, 300*time.Millisecond, 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("Wait() succeeded on closed port, want timeout")
+	}
+	if !strings.Contains(err.Error(), "tcp "+addr+" not ready after 300ms") {
+		t.Errorf("Wait() error = %q, want probe description and timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Wait() took %s, want about 300ms", elapsed)
+	}
+}
+
+func TestWait_BecomesReady(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.log")
+	os.WriteFile(path, nil, 0644)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.WriteFile(path, []byte("listening on :8080\n"), 0644)
+	}()
+
+	p := Log{Path: path, Pattern: regexp.MustCompile(`listening on`)}
+	if err := Wait(context.Background(), p, 5*time.Second, 20*time.Millisecond); err != nil {
+		t.Errorf("Wait() failed: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Package redact masks secrets in log lines before they are written to disk.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Marker replaces every redacted secret.
+const Marker = "[REDACTED]"
+
+// presets are the built-in rules, applied in this order. When a rule has a
+// capture group, only the group is replaced, so the context around the
+// secret (the scheme and user of a URL, "Bearer") stays readable.
+var presets = []struct {
+	name, pattern string
+}{
+	{"bearer", `(?i)\bbearer\s+([A-Za-z0-9._~+/-]+=*)`},
+	{"jwt", `\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`},
+	{"aws", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|(?i:aws_secret_access_key)["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`},
+	{"url-credentials", `[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]*:([^/\s@]+)@`},
+	{"email", `[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`},
+}
+
+// PresetNames returns the names of the built-in rules.
+func PresetNames() []string {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Redactor replaces the matches of its rules with Marker. A nil Redactor
+// leaves text unchanged.
+type Redactor struct {
+	rules []*regexp.Regexp
+}
+
+// New builds a Redactor from preset names and extra regular expressions.
+// It returns nil when there are no rules.
+func New(presetNames, patterns []string) (*Redactor, error) {
+	for _, name := range presetNames {
+		if !IsPreset(name) {
+			return nil, fmt.Errorf("unknown redact preset '%s' (want one of %s)", name, strings.Join(PresetNames(), ", "))
+		}
+	}
+	var rules []*regexp.Regexp
+	for _, p := range presets {
+		for _, name := range presetNames {
+			if strings.EqualFold(name, p.name) {
+				rules = append(rules, regexp.MustCompile(p.pattern))
+				break
+			}
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern: %w", err)
+		}
+		rules = append(rules, re)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &Redactor{rules: rules}, nil
+}
+
+// IsPreset reports whether name is a built-in rule (case-insensitive).
+func IsPreset(name string) bool {
+	for _, p := range presets {
+		if strings.EqualFold(name, p.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns s with every secret replaced by Marker.
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.rules {
+		s = replace(re, s)
+	}
+	return s
+}
+
+// replace replaces the matches of re in s: the first capture group that took
+// part in the match if there is one, otherwise the whole match.
+func replace(re *regexp.Regexp, s string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		for g := 2; g+1 < len(m); g += 2 {
+			if m[g] >= 0 {
+				start, end = m[g], m[g+1]
+				break
+			}
+		}
+		if start == end {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(Marker)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Command-line flags that pass rules to `devlog pipe` and devlog-host.
+const (
+	PresetFlag  = "--redact-preset"
+	PatternFlag = "--redact-pattern"
+)
+
+// Args returns the flags for presets and patterns, to be read back with
+// ParseArgs.
+func Args(presetNames, patterns []string) []string {
+	var args []string
+	for _, name := range presetNames {
+		args = append(args, PresetFlag, name)
+	}
+	for _, pattern := range patterns {
+		args = append(args, PatternFlag, pattern)
+	}
+	return args
+}
+
+// ParseArgs takes the redact flags out of args and builds a Redactor from
+// them. It returns the other arguments in order.
+func ParseArgs(args []string) (*Redactor, []string, error) {
+	var presetNames, patterns, rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case PresetFlag, PatternFlag:
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("%s requires a value", args[i])
+			}
+			if args[i] == PresetFlag {
+				presetNames = append(presetNames, args[i+1])
+			} else {
+				patterns = append(patterns, args[i+1])
+			}
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	r, err := New(presetNames, patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, rest, nil
+}
@@ -0,0 +1,90 @@
+package tmux
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jellydn/devlog/internal/shellescape"
+)
+
+var (
+	traceMu sync.Mutex
+	trace   io.Writer
+)
+
+// SetTrace makes every tmux command echo its command line to w before it
+// runs (e.g. for --verbose). A nil w turns tracing off.
+func SetTrace(w io.Writer) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	trace = w
+}
+
+// Command returns an exec.Cmd that runs tmux with args. All tmux invocations
+// go through it so they can be traced.
+func Command(args ...string) *exec.Cmd {
+	traceString(traceLine(args))
+	return exec.Command("tmux", args...)
+}
+
+// Server selects the tmux server commands talk to. The zero value is the
+// user's default server.
+type Server struct {
+	Socket string // socket name (-L), or a path (-S) when it contains a slash
+	Config string // config file the server is started with (-f), optional
+}
+
+// EmptyConfig is the config file a server on its own socket starts with when
+// Server.Config is unset, so the user's ~/.tmux.conf doesn't apply to it.
+const EmptyConfig = "/dev/null"
+
+// Args returns the global tmux flags that select s.
+func (s Server) Args() []string {
+	var args []string
+	switch {
+	case strings.ContainsRune(s.Socket, '/'):
+		args = append(args, "-S", s.Socket)
+	case s.Socket != "":
+		args = append(args, "-L", s.Socket)
+	}
+	switch {
+	case s.Config != "":
+		args = append(args, "-f", s.Config)
+	case s.Socket != "":
+		args = append(args, "-f", EmptyConfig)
+	}
+	return args
+}
+
+// Command is like the package-level Command, for a command run on s.
+func (s Server) Command(args ...string) *exec.Cmd {
+	return Command(append(s.Args(), args...)...)
+}
+
+// traceString writes line to the trace writer, if any.
+func traceString(line string) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if trace != nil {
+		fmt.Fprintf(trace, "+ %s\n", line)
+	}
+}
+
+// plainArgRegex matches arguments that read the same with or without quotes.
+var plainArgRegex = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// traceLine formats a tmux command line, quoting arguments only when needed.
+func traceLine(args []string) string {
+	parts := []string{"tmux"}
+	for _, arg := range args {
+		if !plainArgRegex.MatchString(arg) {
+			arg = shellescape.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
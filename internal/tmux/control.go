@@ -0,0 +1,175 @@
+package tmux
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// controlClient runs tmux commands over a single control-mode connection
+// (tmux -C), so building a session does not fork tmux for every command.
+// Each command's reply is the %begin ... %end (or %error) block tmux sends
+// back; notifications between blocks are ignored.
+type controlClient struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  bytes.Buffer
+	replies chan controlReply
+	closing chan struct{}
+	done    chan struct{} // closed when stdout is drained
+	mu      sync.Mutex    // one command in flight at a time
+}
+
+type controlReply struct {
+	output string
+	err    error
+}
+
+// startControl starts a control-mode client on server running args as its
+// first command, normally new-session, which also attaches the client to the
+// new session. It returns the first command's output.
+func startControl(server Server, args ...string) (*controlClient, string, error) {
+	c := &controlClient{
+		cmd:     server.Command(append([]string{"-C"}, args...)...),
+		replies: make(chan controlReply),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	c.cmd.Stderr = &c.stderr
+	stdin, err := c.cmd.StdinPipe()
+	if err != nil {
+		return nil, "", err
+	}
+	stdout, err := c.cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", err
+	}
+	c.stdin = stdin
+	if err := c.cmd.Start(); err != nil {
+		return nil, "", err
+	}
+	go c.read(stdout)
+
+	reply, ok := <-c.replies
+	if !ok {
+		err := c.Close()
+		if msg := strings.TrimSpace(c.stderr.String()); msg != "" {
+			err = errors.New(msg)
+		}
+		return nil, "", fmt.Errorf("tmux control client exited: %v", err)
+	}
+	if reply.err != nil {
+		c.Close()
+		return nil, "", reply.err
+	}
+
+	// Pane output is not needed here; tmux before 3.2 rejects the flag
+	c.Run("refresh-client", "-f", "no-output")
+	return c, reply.output, nil
+}
+
+// Run sends one command and waits for its reply.
+func (c *controlClient) Run(args ...string) (string, error) {
+	outputs, err := c.RunList(args)
+	if err != nil {
+		return "", err
+	}
+	return outputs[0], nil
+}
+
+// RunList sends commands as one command list ("a ; b"), which tmux runs in
+// one go, and returns their outputs. tmux skips the rest of a list after a
+// command fails and sends no reply for the skipped commands, so the error
+// returned is the failed command's.
+func (c *controlClient) RunList(commands ...[]string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	lines := make([]string, len(commands))
+	for i, args := range commands {
+		lines[i] = controlLine(args)
+	}
+	line := strings.Join(lines, " ; ")
+	traceString("(control) " + line)
+	if _, err := io.WriteString(c.stdin, line+"\n"); err != nil {
+		return nil, fmt.Errorf("tmux control client: %w", err)
+	}
+	outputs := make([]string, 0, len(commands))
+	for range commands {
+		reply, ok := <-c.replies
+		if !ok {
+			return nil, fmt.Errorf("tmux control client exited")
+		}
+		if reply.err != nil {
+			return nil, reply.err
+		}
+		outputs = append(outputs, reply.output)
+	}
+	return outputs, nil
+}
+
+// Close detaches the client (the session keeps running) and waits for it to exit.
+func (c *controlClient) Close() error {
+	close(c.closing)
+	c.stdin.Close()
+	<-c.done
+	return c.cmd.Wait()
+}
+
+// read parses tmux's control-mode output into replies.
+func (c *controlClient) read(stdout io.Reader) {
+	defer close(c.done)
+	defer close(c.replies)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var number string // command number of the open block, "" outside blocks
+	var lines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if number == "" {
+			if len(fields) >= 3 && fields[0] == "%begin" {
+				number, lines = fields[2], nil
+			}
+			continue
+		}
+		if len(fields) >= 3 && (fields[0] == "%end" || fields[0] == "%error") && fields[2] == number {
+			reply := controlReply{output: strings.Join(lines, "\n")}
+			if fields[0] == "%error" {
+				reply = controlReply{err: errors.New(reply.output)}
+			}
+			number = ""
+			select {
+			case c.replies <- reply:
+			case <-c.closing:
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+}
+
+// controlLine formats args as a tmux command line, quoting arguments that
+// are not plain words. Double quotes keep tmux from expanding $ and ~.
+func controlLine(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = controlQuote(arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+var controlEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func controlQuote(arg string) string {
+	if plainArgRegex.MatchString(arg) {
+		return arg
+	}
+	return `"` + controlEscaper.Replace(arg) + `"`
+}
@@ -0,0 +1,89 @@
+package tmux
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestControlQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"send-keys", "send-keys"},
+		{"%3", "%3"},
+		{"", `""`},
+		{"#{pane_id}", `"#{pane_id}"`},
+		{`say "hi" $HOME ~ \ ;`, `"say \"hi\" \$HOME ~ \\ ;"`},
+		{"a\nb", `"a\nb"`},
+	}
+	for _, tt := range tests {
+		if got := controlQuote(tt.in); got != tt.want {
+			t.Errorf("controlQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestControlClient(t *testing.T) {
+	session := "test-control"
+	server := testServer(t)
+
+	client, paneID, err := startControl(server, "new-session", "-P", "-F", "#{pane_id}", "-s", session)
+	if err != nil {
+		t.Fatalf("startControl() error = %v", err)
+	}
+	if !strings.HasPrefix(paneID, "%") {
+		t.Errorf("new-session output = %q, want a pane id", paneID)
+	}
+
+	value := `it's "quoted" $HOME`
+	if _, err := client.Run("set-option", "-p", "-t", paneID, "@devlog_test", value); err != nil {
+		t.Fatalf("set-option error = %v", err)
+	}
+	got, err := client.Run("display-message", "-p", "-t", paneID, "#{@devlog_test}")
+	if err != nil || got != value {
+		t.Errorf("display-message = %q, %v; want %q", got, err, value)
+	}
+	if _, err := client.Run("no-such-command"); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("unknown command error = %v", err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if !NewServerRunner(session, server).SessionExists() {
+		t.Error("session should keep running after the control client detaches")
+	}
+}
+
+func TestControlClient_RunList(t *testing.T) {
+	client, _, err := startControl(testServer(t), "new-session", "-s", "test-control-list")
+	if err != nil {
+		t.Fatalf("startControl() error = %v", err)
+	}
+	defer client.Close()
+
+	outputs, err := client.RunList([]string{"display-message", "-p", "one;"}, []string{"display-message", "-p", "two"})
+	if err != nil || strings.Join(outputs, " ") != "one; two" {
+		t.Errorf("RunList() = %q, %v; want [one; two]", outputs, err)
+	}
+	// tmux skips the rest of the list after a failure; the next command
+	// still gets its own reply
+	if _, err := client.RunList([]string{"select-layout", "-t", "%999", "tiled"}, []string{"display-message", "-p", "skipped"}); err == nil {
+		t.Error("RunList() with a failing command succeeded")
+	}
+	if got, err := client.Run("display-message", "-p", "after"); err != nil || got != "after" {
+		t.Errorf("display-message after a failed list = %q, %v; want after", got, err)
+	}
+}
+
+func TestStartControl_DuplicateSession(t *testing.T) {
+	session := "test-control-dup"
+	server := testServer(t)
+	if err := server.Command("new-session", "-d", "-s", session).Run(); err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+
+	if _, _, err := startControl(server, "new-session", "-s", session); err == nil || !strings.Contains(err.Error(), "duplicate session") {
+		t.Errorf("startControl() error = %v, want duplicate session", err)
+	}
+}
@@ -0,0 +1,89 @@
+package tmux
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PaneEventsFile is the file in the run directory that `devlog run` appends
+// the start and exit events of pane commands to, one JSON object per line.
+const PaneEventsFile = "pane-events.jsonl"
+
+// Pane event kinds.
+const (
+	EventStart = "start"
+	EventExit  = "exit"
+)
+
+// PaneEvent is one line of PaneEventsFile.
+type PaneEvent struct {
+	Pane   string    `json:"pane"`             // tmux pane id, e.g. "%3"
+	Name   string    `json:"name,omitempty"`   // configured pane name
+	Event  string    `json:"event"`            // EventStart or EventExit
+	Code   *int      `json:"code,omitempty"`   // exit status, for EventExit
+	Signal int       `json:"signal,omitempty"` // signal that killed the command, for EventExit
+	Time   time.Time `json:"time"`             // UTC, to the millisecond
+}
+
+// ReadPaneEvents reads the events recorded in runDir, oldest first. A missing
+// file means no pane has started yet and is not an error; malformed lines
+// (e.g. one cut short by a crash) are skipped.
+func ReadPaneEvents(runDir string) ([]PaneEvent, error) {
+	f, err := os.Open(filepath.Join(runDir, PaneEventsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []PaneEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e PaneEvent
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Pane != "" {
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// LastPaneEvents returns the latest event of every pane in runDir, by pane
+// id, ignoring events before since: pane ids are reused by later sessions
+// writing to the same run directory (run_mode: overwrite).
+func LastPaneEvents(runDir string, since time.Time) (map[string]PaneEvent, error) {
+	events, err := ReadPaneEvents(runDir)
+	if err != nil {
+		return nil, err
+	}
+	last := make(map[string]PaneEvent)
+	for _, e := range events {
+		if !e.Time.Before(since) {
+			last[e.Pane] = e
+		}
+	}
+	return last, nil
+}
+
+// appendPaneEvent appends e to file as one line. The line is written with
+// a single write on a file opened for appending, so events from panes
+// running at the same time don't interleave.
+func appendPaneEvent(file string, e PaneEvent) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
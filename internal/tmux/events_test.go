@@ -0,0 +1,92 @@
+package tmux
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPaneCommand_RecordsEvents(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%4")
+	dir := t.TempDir()
+	c := PaneCommand{Command: "sleep 0.01; exit 7", Name: `api "50%"`, Events: filepath.Join(dir, PaneEventsFile)}
+	c.Run(io.Discard)
+
+	got, err := ReadPaneEvents(dir)
+	if err != nil {
+		t.Fatalf("ReadPaneEvents() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Event != EventStart || got[1].Event != EventExit {
+		t.Fatalf("events = %+v, want start and exit", got)
+	}
+	exit := got[1]
+	if exit.Pane != "%4" || exit.Name != `api "50%"` || exit.Code == nil || *exit.Code != 7 {
+		t.Errorf("exit event = %+v", exit)
+	}
+	if time.Since(exit.Time) > time.Minute {
+		t.Errorf("exit time = %s, want now", exit.Time)
+	}
+	if d := exit.Time.Sub(got[0].Time); d < 10*time.Millisecond || d%time.Millisecond != 0 {
+		t.Errorf("run took %s by the event times, want at least 10ms, to the millisecond", d)
+	}
+}
+
+func TestPaneCommand_RecordsSignal(t *testing.T) {
+	tests := []struct {
+		command string
+		code    int
+		signal  int
+	}{
+		{"kill -KILL $$", 137, 9},
+		{"sleep 0; exit 137", 137, 0},
+	}
+	t.Setenv("TMUX_PANE", "%1")
+	for _, tt := range tests {
+		dir := t.TempDir()
+		c := PaneCommand{Command: tt.command, Events: filepath.Join(dir, PaneEventsFile)}
+		c.Run(io.Discard)
+
+		events, err := ReadPaneEvents(dir)
+		if err != nil || len(events) != 2 {
+			t.Fatalf("%s: events = %+v, %v", tt.command, events, err)
+		}
+		exit := events[1]
+		if exit.Code == nil || *exit.Code != tt.code || exit.Signal != tt.signal {
+			t.Errorf("%s: exit event = %+v, want code %d, signal %d", tt.command, exit, tt.code, tt.signal)
+		}
+	}
+}
+
+func TestLastPaneEvents(t *testing.T) {
+	dir := t.TempDir()
+	data := `{"pane":"%3","event":"start","time":"2026-01-01T09:00:00Z"}
+{"pane":"%1","name":"api","event":"start","time":"2026-01-02T14:00:00Z"}
+{"pane":"%2","event":"start","time":"2026-01-02T14:00:00Z"}
+{"pane":"%1","name":"api","event":"exit","code":137,"signal":9,"time":"2026-01-02T14:02:00Z"}
+{"pane":"%2","event":"ex
+`
+	if err := os.WriteFile(filepath.Join(dir, PaneEventsFile), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := LastPaneEvents(dir, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("LastPaneEvents() error = %v", err)
+	}
+	api := last["%1"]
+	if api.Event != EventExit || api.Signal != 9 {
+		t.Errorf("last event of %%1 = %+v, want exit by signal 9", api)
+	}
+	if last["%2"].Event != EventStart {
+		t.Errorf("last event of %%2 = %+v, want start (truncated line skipped)", last["%2"])
+	}
+	if _, ok := last["%3"]; ok {
+		t.Error("event from before the session was included")
+	}
+
+	if events, err := ReadPaneEvents(t.TempDir()); err != nil || events != nil {
+		t.Errorf("ReadPaneEvents() on a run without events = %v, %v", events, err)
+	}
+}
@@ -0,0 +1,409 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/logrotate"
+	"github.com/jellydn/devlog/internal/redact"
+	"github.com/jellydn/devlog/internal/shellescape"
+)
+
+// Plan is everything CreateSession does for a config, built before anything
+// runs so it can be printed (devlog up --dry-run, devlog plan) or executed.
+//
+// The first step (new-session) starts a single control-mode client (tmux -C)
+// and every later step and pane start is sent over that connection. tmux
+// assigns pane ids when panes are created, so steps refer to panes by
+// placeholders of the form "%<window>.<pane>" (indexes, e.g. "%1.0"), which
+// Execute replaces with the ids that new-session, new-window and
+// split-window print (-P -F '#{pane_id}').
+type Plan struct {
+	Session  string
+	Server   Server   // tmux server the session runs on
+	RunDir   string   // created before the session starts
+	LogFiles []string // created empty so pipe-pane can append to them
+	Steps    []Step   // create and prepare panes, in order
+	Starts   []Start  // pane commands and their logs, sent in depends_on order
+
+	slots   []paneSlot // slot.id holds the pane placeholder
+	created []string   // files and directories Execute created, for Rollback
+	started bool       // Execute created the tmux session
+}
+
+// Step is one tmux command of a Plan.
+type Step struct {
+	Args []string
+	// Pane is set when the step creates a pane: the id tmux prints replaces
+	// this placeholder in later steps.
+	Pane string
+}
+
+// Start sends a pane's command once the panes it depends on are ready.
+type Start struct {
+	Pane      string   // pane label
+	DependsOn []string // panes that must be ready first
+	Ready     string   // readiness probe, "" when the pane has none
+	Args      []string // respawn-pane arguments
+	Pipe      []string // pipe-pane arguments sent along with Args, nil when the pane has no log
+}
+
+// paneRef is the placeholder for a pane until tmux assigns its id.
+func paneRef(window, pane int) string {
+	return fmt.Sprintf("%%%d.%d", window, pane)
+}
+
+// Plan builds the plan for creating cfg's session without running anything.
+func (r *Runner) Plan(cfg SessionConfig) (*Plan, error) {
+	if len(cfg.Windows) == 0 || len(cfg.Windows[0].Panes) == 0 {
+		return nil, fmt.Errorf("at least one window with one pane is required")
+	}
+
+	logsDir := cfg.RunDir
+	if logsDir == "" {
+		logsDir = RunDir(cfg.LogsDir, cfg.RunMode, time.Now())
+	}
+	// Stored as an absolute path so it resolves from any working directory
+	absLogsDir, err := filepath.Abs(logsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path for logs dir: %w", err)
+	}
+
+	start := time.Now()
+	p := &Plan{Session: r.sessionName, Server: r.server, RunDir: logsDir, LogFiles: paneLogFiles(logsDir, cfg.Windows)}
+	add := func(pane string, args ...string) {
+		p.Steps = append(p.Steps, Step{Args: args, Pane: pane})
+	}
+
+	for wi, window := range cfg.Windows {
+		first := paneRef(wi, 0)
+		var args []string
+		if wi == 0 {
+			// No -d: the session is created by the control client, which stays
+			// attached until Execute is done with it
+			args = []string{"new-session", "-P", "-F", "#{pane_id}", "-s", r.sessionName, "-n", window.Name}
+		} else {
+			args = []string{"new-window", "-P", "-F", "#{pane_id}", "-t", r.sessionName, "-n", window.Name}
+		}
+		add(first, appendCwdArg(args, window.PaneCwd(window.Panes[0]))...)
+
+		if wi == 0 {
+			add("", "set-environment", "-t", r.sessionName, "DEVLOG_LOGS_DIR", absLogsDir)
+			if cfg.Profile != "" {
+				add("", "set-environment", "-t", r.sessionName, "DEVLOG_PROFILE", cfg.Profile)
+			}
+		}
+
+		// Panes are targeted by id: window names may contain '.' or ':' or
+		// repeat, and tmux would then resolve them to the wrong target
+		for pi, pane := range window.Panes {
+			ref := paneRef(wi, pi)
+			if pi > 0 {
+				// Split the pane created last, which tmux made active
+				args := append([]string{"split-window"}, splitArgs(pane)...)
+				args = append(args, "-P", "-F", "#{pane_id}", "-t", paneRef(wi, pi-1))
+				add(ref, appendCwdArg(args, window.PaneCwd(pane))...)
+			}
+			if pane.Name != "" {
+				add("", "set-option", "-p", "-t", ref, paneNameOption, pane.Name)
+			}
+			slot := paneSlot{
+				id:     ref,
+				window: window,
+				pane:   pane,
+				events: filepath.Join(absLogsDir, PaneEventsFile),
+				devlog: devlogCommand(cfg),
+			}
+			if pane.Log != "" {
+				slot.pipe = pipeCommand(cfg, logsDir, pane, start)
+			}
+			p.slots = append(p.slots, slot)
+		}
+		if window.Layout != "" {
+			add("", "select-layout", "-t", first, window.Layout)
+		}
+	}
+
+	for _, slot := range p.slots {
+		start := Start{Pane: slot.label(), DependsOn: slot.pane.DependsOn, Args: startArgs(slot), Pipe: pipeArgs(slot)}
+		if slot.pane.Ready.Enabled() {
+			pr, err := readinessProbe(logsDir, slot)
+			if err != nil {
+				return nil, fmt.Errorf("pane %s: %w", slot.label(), err)
+			}
+			start.Ready = pr.String()
+		}
+		p.Starts = append(p.Starts, start)
+	}
+	return p, nil
+}
+
+// defaultDevlog is the devlog binary used when SessionConfig.Devlog is empty.
+// Tests point it at the test binary, which stands in for `devlog run`.
+var defaultDevlog = "devlog"
+
+// devlogCommand returns the devlog binary that runs cfg's pane commands and
+// piped pane logs.
+func devlogCommand(cfg SessionConfig) string {
+	if cfg.Devlog != "" {
+		return cfg.Devlog
+	}
+	return defaultDevlog
+}
+
+// pipeCommand returns the pipe-pane command that writes a pane's output to
+// its log in logsDir: plain `cat`, or `devlog pipe` when the pane's log is
+// processed or secrets are redacted.
+// Paths are quoted to prevent command injection.
+func pipeCommand(cfg SessionConfig, logsDir string, pane config.PaneConfig, start time.Time) string {
+	logPath := config.RunFilePath(logsDir, pane.Log)
+	if !pane.Piped() && !cfg.Redact.Enabled() {
+		return fmt.Sprintf("cat >> %s", shellescape.Quote(logPath))
+	}
+	args := []string{shellescape.Quote(devlogCommand(cfg)), "pipe"}
+	if pane.Timestamps != "" && pane.Timestamps != config.TimestampsNone {
+		args = append(args, "--timestamps", shellescape.Quote(pane.Timestamps), "--start", start.UTC().Format(time.RFC3339Nano))
+	}
+	if pane.Sanitize {
+		args = append(args, "--sanitize")
+	}
+	if pane.RawLog != "" {
+		args = append(args, "--raw", shellescape.Quote(config.RunFilePath(logsDir, pane.RawLog)))
+	}
+	args = append(args, logrotate.Args(pane.LogRotation())...)
+	redactArgs := redact.Args(cfg.Redact.Presets, cfg.Redact.Patterns)
+	for i := 0; i+1 < len(redactArgs); i += 2 {
+		args = append(args, redactArgs[i], shellescape.Quote(redactArgs[i+1]))
+	}
+	args = append(args, shellescape.Quote(logPath))
+	return strings.Join(args, " ")
+}
+
+// Execute runs p: it creates the run directory and log files, runs every
+// step, then starts the panes in depends_on order, reporting readiness
+// progress to progress (optional).
+//
+// If a step fails, Execute rolls back: the partial session is killed and the
+// files it created are removed (see Rollback). A *NotReadyError is not rolled
+// back; the caller decides whether to keep the session for inspection or to
+// call Rollback.
+func (r *Runner) Execute(p *Plan, progress io.Writer) error {
+	if r.SessionExists() {
+		return fmt.Errorf("tmux session '%s' already exists", r.sessionName)
+	}
+	r.logsDir = p.RunDir
+
+	err := r.execute(p, progress)
+	var notReady *NotReadyError
+	if err != nil && !errors.As(err, &notReady) {
+		if rbErr := r.Rollback(p); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+	}
+	return err
+}
+
+func (r *Runner) execute(p *Plan, progress io.Writer) error {
+	if err := p.mkdirAll(p.RunDir); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	for _, logPath := range p.LogFiles {
+		if err := p.createFile(logPath); err != nil {
+			return err
+		}
+	}
+
+	ids := make(map[string]string)
+	resolve := func(args []string) []string {
+		resolved := make([]string, len(args))
+		for i, arg := range args {
+			if id, ok := ids[arg]; ok {
+				arg = id
+			}
+			resolved[i] = arg
+		}
+		return resolved
+	}
+
+	first := p.Steps[0]
+	client, id, err := startControl(r.server, first.Args...)
+	if err != nil {
+		return fmt.Errorf("tmux %s failed: %w", first.Args[0], err)
+	}
+	defer client.Close()
+	p.started = true
+	ids[first.Pane] = strings.TrimSpace(id)
+
+	for _, step := range p.Steps[1:] {
+		args := resolve(step.Args)
+		output, err := client.Run(args...)
+		if err != nil {
+			return fmt.Errorf("tmux %s failed: %w", args[0], err)
+		}
+		if step.Pane != "" {
+			ids[step.Pane] = strings.TrimSpace(output)
+		}
+	}
+
+	slots := make([]paneSlot, len(p.slots))
+	for i, slot := range p.slots {
+		slot.id = ids[slot.id]
+		slots[i] = slot
+	}
+	return r.startPanes(slots, client, progress)
+}
+
+// Rollback undoes an executed plan: it kills the session (if Execute created
+// it) and removes the log files and directories Execute created, as long as
+// nothing has been written to them.
+func (r *Runner) Rollback(p *Plan) error {
+	var errs []error
+	if p.started && r.SessionExists() {
+		if output, err := r.server.Command("kill-session", "-t", r.sessionName).CombinedOutput(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to kill tmux session: %s: %w", strings.TrimSpace(string(output)), err))
+		}
+	}
+	p.started = false
+	// Newest first, so files go before their directories
+	for i := len(p.created) - 1; i >= 0; i-- {
+		if err := RemoveIfEmpty(p.created[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	p.created = nil
+	return errors.Join(errs...)
+}
+
+// RemoveIfEmpty removes path if it is an empty file or an empty directory.
+// Missing and non-empty paths are left alone.
+func RemoveIfEmpty(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil || len(entries) > 0 {
+			return nil
+		}
+	} else if info.Size() > 0 {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
+// RemoveRunDir removes dir when it holds nothing but empty files and the
+// named bookkeeping files (hook output, pane events), which go with it.
+// Directories with any other content are left alone.
+func RemoveRunDir(dir string, bookkeeping ...string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if slices.Contains(bookkeeping, entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || info.Size() > 0 {
+			return nil
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	return nil
+}
+
+// mkdirAll creates dir and its missing parents, recording the ones it made.
+func (p *Plan) mkdirAll(dir string) error {
+	var missing []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		p.created = append(p.created, missing[i])
+	}
+	return nil
+}
+
+// createFile creates an empty log file (and its directory) unless it exists.
+func (p *Plan) createFile(path string) error {
+	logDir := filepath.Dir(path)
+	if err := p.mkdirAll(logDir); err != nil {
+		return fmt.Errorf("failed to create log directory '%s': %w", logDir, err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create log file '%s': %w", path, err)
+	}
+	p.created = append(p.created, path)
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close log file '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Print writes p as a shell-like script: directories and files to create,
+// the control-mode client, then the commands sent over it with pane id
+// placeholders.
+func (p *Plan) Print(w io.Writer) {
+	fmt.Fprintf(w, "mkdir -p %s\n", shellescape.Quote(p.RunDir))
+	for _, f := range p.LogFiles {
+		fmt.Fprintf(w, "touch %s\n", shellescape.Quote(f))
+	}
+	for i, step := range p.Steps {
+		line := "  " + controlLine(step.Args)
+		if i == 0 {
+			line = traceLine(append(append(p.Server.Args(), "-C"), step.Args...))
+		}
+		if step.Pane != "" {
+			line += "  # -> " + step.Pane
+		}
+		fmt.Fprintln(w, line)
+		if i == 0 {
+			fmt.Fprintln(w, "# commands sent over the control-mode connection:")
+		}
+	}
+	fmt.Fprintln(w, "# start panes (each after its depends_on panes are ready)")
+	for _, s := range p.Starts {
+		var notes []string
+		if len(s.DependsOn) > 0 {
+			notes = append(notes, "after "+strings.Join(s.DependsOn, ", "))
+		}
+		if s.Ready != "" {
+			notes = append(notes, "ready: "+s.Ready)
+		}
+		note := s.Pane
+		if len(notes) > 0 {
+			note += " (" + strings.Join(notes, "; ") + ")"
+		}
+		line := controlLine(s.Args)
+		if s.Pipe != nil {
+			line += " ; " + controlLine(s.Pipe)
+		}
+		fmt.Fprintf(w, "  %s  # %s\n", line, note)
+	}
+}
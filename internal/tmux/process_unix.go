@@ -0,0 +1,79 @@
+//go:build !windows
+
+package tmux
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"unsafe"
+)
+
+var signals = map[string]syscall.Signal{
+	"INT":  syscall.SIGINT,
+	"TERM": syscall.SIGTERM,
+	"KILL": syscall.SIGKILL,
+	"HUP":  syscall.SIGHUP,
+	"QUIT": syscall.SIGQUIT,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// signalProcess sends the named signal to pid, or to process group -pid
+// when pid is negative. Errors (e.g. the process already exited) are ignored.
+func signalProcess(pid int, name string) {
+	if sig, ok := signals[name]; ok {
+		syscall.Kill(pid, sig)
+	}
+}
+
+// signalGroup sends sig to the process group led by pid.
+func signalGroup(pid int, sig syscall.Signal) {
+	syscall.Kill(-pid, sig)
+}
+
+// foreground starts cmd in a process group of its own and, when stdin is a
+// terminal (the pane), makes that group the terminal's foreground job, as a
+// shell does with the commands typed into it: Ctrl+C goes to the command, and
+// tmux names the pane after it.
+func foreground(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if _, ok := terminalGroup(); ok {
+		cmd.SysProcAttr.Foreground = true
+		cmd.SysProcAttr.Ctty = 0
+	}
+}
+
+// takeTerminal makes devlog run's own process group the terminal's
+// foreground job again once the command has exited.
+func takeTerminal() {
+	if _, ok := terminalGroup(); !ok {
+		return
+	}
+	// A background group changing the foreground job gets SIGTTOU unless it
+	// ignores it
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	pgrp := int32(syscall.Getpgrp())
+	syscall.Syscall(syscall.SYS_IOCTL, 0, uintptr(syscall.TIOCSPGRP), uintptr(unsafe.Pointer(&pgrp)))
+}
+
+// terminalGroup returns the foreground process group of the terminal on
+// stdin, and false when stdin is not a terminal.
+func terminalGroup() (int, bool) {
+	var pgrp int32
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, 0, uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgrp)))
+	return int(pgrp), errno == 0
+}
+
+// execShell replaces the process with the user's login shell ($SHELL), the
+// program tmux starts in new panes.
+func execShell() error {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return syscall.Exec(shell, []string{"-" + filepath.Base(shell)}, os.Environ())
+}
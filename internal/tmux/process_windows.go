@@ -0,0 +1,22 @@
+//go:build windows
+
+package tmux
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// signalProcess is a no-op: tmux panes don't run on Windows.
+func signalProcess(pid int, name string) {}
+
+func signalGroup(pid int, sig syscall.Signal) {}
+
+func foreground(cmd *exec.Cmd) {}
+
+func takeTerminal() {}
+
+func execShell() error {
+	return errors.New("devlog run needs tmux, which does not run on Windows")
+}
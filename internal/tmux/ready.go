@@ -0,0 +1,193 @@
+package tmux
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/panelog"
+	"github.com/jellydn/devlog/internal/probe"
+)
+
+// paneSlot is a created pane whose command has not been sent yet.
+type paneSlot struct {
+	id        string // tmux pane id, e.g. "%3"
+	window    config.WindowConfig
+	pane      config.PaneConfig
+	logOffset int64       // size of the pane's log file when its command was started
+	logFile   os.FileInfo // the pane's log file logOffset was measured in
+	events    string      // absolute path of the run's PaneEventsFile
+	devlog    string      // devlog binary the pane's command is run with
+	pipe      string      // pipe-pane command writing the pane's log, "" without a log
+}
+
+// label names the pane in progress and error output.
+func (s paneSlot) label() string {
+	if s.pane.Name != "" {
+		return s.pane.Name
+	}
+	return fmt.Sprintf("%s (pane %s)", s.window.Name, s.id)
+}
+
+// PaneFailure describes a pane that failed its readiness probe or was not
+// started because a dependency failed.
+type PaneFailure struct {
+	Pane   string
+	Reason string
+}
+
+// NotReadyError is returned by CreateSession when readiness probes fail. The
+// session is left running; devlog up rolls it back unless --keep-on-failure.
+type NotReadyError struct {
+	NotReady   []PaneFailure // probe timed out
+	NotStarted []PaneFailure // skipped because a dependency was not ready
+}
+
+func (e *NotReadyError) Error() string {
+	var parts []string
+	for _, f := range e.NotReady {
+		parts = append(parts, fmt.Sprintf("pane '%s' not ready: %s", f.Pane, f.Reason))
+	}
+	for _, f := range e.NotStarted {
+		parts = append(parts, fmt.Sprintf("pane '%s' not started: %s", f.Pane, f.Reason))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// paneRun tracks one pane through startPanes.
+type paneRun struct {
+	slot     paneSlot
+	done     chan struct{} // closed once the pane is ready or has failed
+	sendErr  error         // tmux failed to send the command
+	probeErr error         // readiness probe failed
+	blocked  string        // dependency that kept the pane from starting
+}
+
+func (p *paneRun) ready() bool {
+	return p.sendErr == nil && p.probeErr == nil && p.blocked == ""
+}
+
+// startPanes sends each pane's command once every pane it depends_on is ready.
+// Panes without a ready probe count as ready as soon as their command is sent.
+// Dependencies that are not part of the session (e.g. disabled by a profile)
+// are ignored.
+func (r *Runner) startPanes(slots []paneSlot, client *controlClient, progress io.Writer) error {
+	if progress == nil {
+		progress = io.Discard
+	}
+	var progressMu sync.Mutex
+	report := func(format string, args ...any) {
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		fmt.Fprintf(progress, format+"\n", args...)
+	}
+
+	runs := make([]*paneRun, len(slots))
+	byName := make(map[string]*paneRun)
+	for i, slot := range slots {
+		runs[i] = &paneRun{slot: slot, done: make(chan struct{})}
+		if slot.pane.Name != "" {
+			byName[slot.pane.Name] = runs[i]
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		wg.Add(1)
+		go func(run *paneRun) {
+			defer wg.Done()
+			defer close(run.done)
+
+			for _, dep := range run.slot.pane.DependsOn {
+				depRun, ok := byName[dep]
+				if !ok {
+					continue
+				}
+				<-depRun.done
+				if !depRun.ready() {
+					run.blocked = dep
+					return
+				}
+			}
+
+			// Readiness log probes only look at output written after this
+			// offset: the command's own, not earlier runs' or other panes'
+			if run.slot.pane.Log != "" {
+				if info, err := os.Stat(config.RunFilePath(r.logsDir, run.slot.pane.Log)); err == nil {
+					run.slot.logOffset, run.slot.logFile = info.Size(), info
+				}
+			}
+			if run.sendErr = sendCommand(client, run.slot); run.sendErr != nil {
+				return
+			}
+
+			ready := run.slot.pane.Ready
+			if !ready.Enabled() {
+				return
+			}
+			p, err := readinessProbe(r.logsDir, run.slot)
+			if err != nil {
+				run.probeErr = err
+				return
+			}
+			report("Waiting for %s (%s)...", run.slot.label(), p)
+			start := time.Now()
+			if run.probeErr = probe.Wait(context.Background(), p, ready.TimeoutDuration(), ready.IntervalDuration()); run.probeErr != nil {
+				report("Pane %s is not ready: %v", run.slot.label(), run.probeErr)
+				return
+			}
+			report("Pane %s is ready (%s)", run.slot.label(), time.Since(start).Round(100*time.Millisecond))
+		}(run)
+	}
+	wg.Wait()
+
+	notReady := &NotReadyError{}
+	for _, run := range runs {
+		switch {
+		case run.sendErr != nil:
+			return fmt.Errorf("failed to start pane %s: %w", run.slot.label(), run.sendErr)
+		case run.probeErr != nil:
+			notReady.NotReady = append(notReady.NotReady, PaneFailure{Pane: run.slot.label(), Reason: run.probeErr.Error()})
+		case run.blocked != "":
+			notReady.NotStarted = append(notReady.NotStarted, PaneFailure{
+				Pane:   run.slot.label(),
+				Reason: fmt.Sprintf("dependency '%s' is not ready", run.blocked),
+			})
+		}
+	}
+	if len(notReady.NotReady) > 0 || len(notReady.NotStarted) > 0 {
+		return notReady
+	}
+	return nil
+}
+
+// readinessProbe builds the probe described by the pane's ready config; log
+// probes read the pane's log in logsDir.
+func readinessProbe(logsDir string, slot paneSlot) (probe.Probe, error) {
+	ready := slot.pane.Ready
+	switch {
+	case ready.TCP != "":
+		return probe.TCP{Address: ready.TCPAddress()}, nil
+	case ready.HTTP != "":
+		return probe.HTTP{URL: ready.HTTP, Status: ready.Status}, nil
+	default:
+		pattern, err := regexp.Compile(ready.Log)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ready.log pattern: %w", err)
+		}
+		return probe.Log{
+			Path:       config.RunFilePath(logsDir, slot.pane.Log),
+			Pattern:    pattern,
+			Offset:     slot.logOffset,
+			OffsetFile: slot.logFile,
+			// devlog pipe's timestamps are not part of the command's output
+			Prefix: panelog.TimestampPrefix(slot.pane.Timestamps),
+		}, nil
+	}
+}
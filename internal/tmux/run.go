@@ -0,0 +1,209 @@
+package tmux
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+// RunCommand is the devlog subcommand every pane is started with (tmux
+// respawn-pane): `devlog run [flags] -- <command>` runs a PaneCommand.
+const RunCommand = "run"
+
+// Command-line flags that pass a PaneCommand to `devlog run`.
+const (
+	EventsFlag     = "--events"
+	NameFlag       = "--name"
+	LabelFlag      = "--label"
+	EnvFlag        = "--env"
+	RestartFlag    = "--restart"
+	MaxRetriesFlag = "--max-retries"
+	BackoffFlag    = "--backoff"
+)
+
+// PaneCommand is a pane's command as `devlog run` runs it. devlog run is the
+// pane's own process rather than a line typed into the pane's shell: it runs
+// the command with sh in the pane's foreground, records the start and exit of
+// every run in Events, restarts it according to Restart, and then hands the
+// pane over to the user's shell.
+type PaneCommand struct {
+	Command    string
+	Env        map[string]string // added to the command's environment
+	Name       string            // configured pane name, recorded with its events
+	Label      string            // names the pane in restart markers
+	Events     string            // file the runs' PaneEvents are appended to
+	Restart    string            // config.RestartNever (default), RestartOnFailure or RestartAlways
+	MaxRetries int               // restarts before giving up; 0 means no limit
+	Backoff    time.Duration     // delay before the first restart, doubled after each
+}
+
+// newPaneCommand returns the command slot's pane is started with.
+func newPaneCommand(slot paneSlot) PaneCommand {
+	c := PaneCommand{
+		Command: slot.pane.Cmd,
+		Env:     slot.window.PaneEnv(slot.pane),
+		Name:    slot.pane.Name,
+		Label:   slot.label(),
+		Events:  slot.events,
+	}
+	if slot.pane.Supervised() {
+		c.Restart = slot.pane.Restart
+		c.MaxRetries = slot.pane.MaxRetries
+		c.Backoff = slot.pane.BackoffDuration()
+	}
+	return c
+}
+
+// Args returns the `devlog run` arguments for c, to be read back with
+// ParsePaneCommand. The command itself comes last, after "--".
+func (c PaneCommand) Args() []string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" {
+			args = append(args, flag, value)
+		}
+	}
+	add(EventsFlag, c.Events)
+	add(NameFlag, c.Name)
+	add(LabelFlag, c.Label)
+	names := make([]string, 0, len(c.Env))
+	for name := range c.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(EnvFlag, name+"="+c.Env[name])
+	}
+	if c.Restart != "" && c.Restart != config.RestartNever {
+		add(RestartFlag, c.Restart)
+		if c.MaxRetries > 0 {
+			add(MaxRetriesFlag, strconv.Itoa(c.MaxRetries))
+		}
+		add(BackoffFlag, c.Backoff.String())
+	}
+	return append(args, "--", c.Command)
+}
+
+// ParsePaneCommand reads the arguments of `devlog run` into a PaneCommand.
+func ParsePaneCommand(args []string) (PaneCommand, error) {
+	c := PaneCommand{Backoff: config.DefaultRestartBackoff}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i != len(args)-2 {
+				return c, fmt.Errorf("expected one command after --")
+			}
+			c.Command = args[i+1]
+			return c, nil
+		}
+		if i+1 >= len(args) {
+			return c, fmt.Errorf("%s requires a value", arg)
+		}
+		i++
+		value := args[i]
+		switch arg {
+		case EventsFlag:
+			c.Events = value
+		case NameFlag:
+			c.Name = value
+		case LabelFlag:
+			c.Label = value
+		case EnvFlag:
+			name, v, ok := strings.Cut(value, "=")
+			if !ok || name == "" {
+				return c, fmt.Errorf("%s must be NAME=VALUE, got '%s'", arg, value)
+			}
+			if c.Env == nil {
+				c.Env = make(map[string]string)
+			}
+			c.Env[name] = v
+		case RestartFlag:
+			switch value {
+			case config.RestartNever, config.RestartOnFailure, config.RestartAlways:
+				c.Restart = value
+			default:
+				return c, fmt.Errorf("%s must be 'never', 'on-failure' or 'always', got '%s'", arg, value)
+			}
+		case MaxRetriesFlag:
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return c, fmt.Errorf("%s must be a non-negative number, got '%s'", arg, value)
+			}
+			c.MaxRetries = n
+		case BackoffFlag:
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return c, fmt.Errorf("%s must be a positive duration, got '%s'", arg, value)
+			}
+			c.Backoff = d
+		default:
+			return c, fmt.Errorf("unknown argument: %s", arg)
+		}
+	}
+	return c, fmt.Errorf("command is required after --")
+}
+
+// RunPane is `devlog run`: it runs the PaneCommand in args, then replaces
+// itself with the user's shell, so the pane stays open as if the command
+// had been typed into it. It returns early only when the pane is going away
+// (devlog run was sent SIGTERM or SIGHUP) or on errors.
+func RunPane(args []string) error {
+	c, err := ParsePaneCommand(args)
+	if err != nil {
+		return err
+	}
+	if c.Run(os.Stdout) {
+		return nil
+	}
+	return execShell()
+}
+
+// shellArgs runs command with POSIX sh, so bash-style syntax works even when
+// the user's shell is fish or zsh. sh replaces itself (exec) with a lone
+// simple command, so that tmux shows the command's name as the pane's
+// current command and signals reach it first-hand.
+func shellArgs(command string) []string {
+	if simpleCommand(command) {
+		command = "exec " + command
+	}
+	return []string{"sh", "-lc", command}
+}
+
+// shellOperators are the characters that may join several commands into one
+// command line, or start a subshell.
+const shellOperators = ";&|()`\n"
+
+// shellReserved are the keywords and builtins that exec can't run.
+var shellReserved = map[string]bool{
+	"!": true, "{": true, "if": true, "case": true, "for": true, "while": true, "until": true,
+	".": true, ":": true, "alias": true, "break": true, "cd": true, "command": true, "continue": true,
+	"eval": true, "exec": true, "exit": true, "export": true, "readonly": true, "return": true,
+	"set": true, "shift": true, "source": true, "trap": true, "ulimit": true, "umask": true,
+	"unset": true, "wait": true,
+}
+
+// simpleCommand reports whether command is a single simple command that exec
+// can run: no operators, no leading variable assignments, no shell keyword
+// or builtin.
+func simpleCommand(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || strings.ContainsAny(command, shellOperators) {
+		return false
+	}
+	return !strings.Contains(fields[0], "=") && !shellReserved[fields[0]]
+}
+
+// exitStatus returns the shell-style exit status of a finished process,
+// 128+n when signal n killed it, and that signal.
+func exitStatus(state *os.ProcessState) (int, syscall.Signal) {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return 128 + int(ws.Signal()), ws.Signal()
+	}
+	return state.ExitCode(), 0
+}
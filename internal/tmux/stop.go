@@ -0,0 +1,255 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+// StopOptions controls how StopSession stops pane commands. Empty fields use
+// config.DefaultShutdownSignals and config.DefaultShutdownTimeout.
+type StopOptions struct {
+	Signals []string      // signal names sent in order, e.g. INT, TERM, KILL
+	Timeout time.Duration // wait for the panes to exit after each signal
+}
+
+// PaneStop reports a pane whose processes did not exit after the first signal.
+type PaneStop struct {
+	Pane    string
+	Signal  string // signal after which its processes had exited; "" if they never did
+	Forced  bool   // it took the last signal of the sequence
+	Running bool   // processes were still running after the last signal
+}
+
+// stopTarget is a pane shell and the processes started from it that
+// StopSession signals and waits for.
+type stopTarget struct {
+	pane   string
+	groups map[int]bool // process groups of the pane's commands
+	pids   map[int]bool // processes in the shell's own group
+}
+
+// alive reports whether any of the target's processes are still running.
+// Zombies count as exited: they only wait for their parent to reap them.
+func (t *stopTarget) alive(procs []process) bool {
+	for _, p := range procs {
+		if !p.zombie && (t.groups[p.pgid] || t.pids[p.pid]) {
+			return true
+		}
+	}
+	return false
+}
+
+// signal sends sig to every process group and process of the target.
+func (t *stopTarget) signal(sig string) {
+	for pgid := range t.groups {
+		signalProcess(-pgid, sig)
+	}
+	for pid := range t.pids {
+		signalProcess(pid, sig)
+	}
+}
+
+// KillSession stops all panes with the default signal sequence and kills the
+// tmux session
+func (r *Runner) KillSession() error {
+	_, err := r.StopSession(StopOptions{})
+	return err
+}
+
+// StopSession signals the processes running in every pane with each of
+// opts.Signals in turn, waiting up to opts.Timeout after each for them to
+// exit, then kills the session. Panes are signalled by process group, so
+// children that ignore the first signal or outlive their parent are still
+// reached by the later ones. It returns the panes that needed more than the
+// first signal. The session is killed even when the panes' processes could
+// not be listed; such errors are returned after it.
+func (r *Runner) StopSession(opts StopOptions) ([]PaneStop, error) {
+	if !r.SessionExists() {
+		return nil, fmt.Errorf("tmux session '%s' does not exist", r.sessionName)
+	}
+	if len(opts.Signals) == 0 {
+		opts.Signals = config.DefaultShutdownSignals
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = config.DefaultShutdownTimeout
+	}
+
+	var errs []error
+	targets, err := r.stopTargets()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to get pane processes: %w", err))
+	}
+
+	var stops []PaneStop
+	for i, sig := range opts.Signals {
+		if len(targets) == 0 {
+			break
+		}
+		for _, t := range targets {
+			t.signal(sig)
+		}
+		if targets, err = waitForExit(targets, opts.Timeout); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if i == 0 {
+			for _, t := range targets {
+				stops = append(stops, PaneStop{Pane: t.pane})
+			}
+			continue
+		}
+		for j := range stops {
+			if stops[j].Signal == "" && !containsPane(targets, stops[j].Pane) {
+				stops[j].Signal = sig
+				stops[j].Forced = i == len(opts.Signals)-1
+			}
+		}
+	}
+	for j := range stops {
+		stops[j].Running = stops[j].Signal == ""
+	}
+
+	// Kill the session (this will close all panes and flush logs)
+	cmd := r.server.Command("kill-session", "-t", r.sessionName)
+	if err := cmd.Run(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to kill tmux session: %w", err))
+	}
+
+	return stops, errors.Join(errs...)
+}
+
+// waitForExit polls targets until all have exited or timeout passes, and
+// returns the ones still running.
+func waitForExit(targets []*stopTarget, timeout time.Duration) ([]*stopTarget, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		procs, err := listProcesses()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pane processes: %w", err)
+		}
+		var running []*stopTarget
+		for _, t := range targets {
+			if t.alive(procs) {
+				running = append(running, t)
+			}
+		}
+		if len(running) == 0 || !time.Now().Before(deadline) {
+			return running, nil
+		}
+		targets = running
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func containsPane(targets []*stopTarget, pane string) bool {
+	for _, t := range targets {
+		if t.pane == pane {
+			return true
+		}
+	}
+	return false
+}
+
+// stopTargets finds the processes running under every pane's shell. Panes
+// with nothing running besides their shell are left out.
+func (r *Runner) stopTargets() ([]*stopTarget, error) {
+	output, err := r.server.Command("list-panes", "-s", "-t", r.sessionName, "-F", "#{pane_id}\t#{pane_pid}\t#{window_name}\t#{"+paneNameOption+"}").Output()
+	if err != nil {
+		return nil, err
+	}
+	procs, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*stopTarget
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 4 {
+			continue
+		}
+		shell, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		label := parts[3]
+		if label == "" {
+			label = fmt.Sprintf("%s (pane %s)", parts[2], parts[0])
+		}
+		t := &stopTarget{pane: label, groups: make(map[int]bool), pids: make(map[int]bool)}
+		for _, p := range descendants(procs, shell) {
+			if p.pgid == shell {
+				t.pids[p.pid] = true
+			} else {
+				t.groups[p.pgid] = true
+			}
+		}
+		if len(t.groups)+len(t.pids) > 0 {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// process is one line of `ps -A -o pid=,ppid=,pgid=,stat=`.
+type process struct {
+	pid, ppid, pgid int
+	zombie          bool
+}
+
+func listProcesses() ([]process, error) {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,pgid=,stat=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+	return parseProcesses(string(output)), nil
+}
+
+func parseProcesses(output string) []process {
+	var procs []process
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		var p process
+		var err error
+		if p.pid, err = strconv.Atoi(fields[0]); err != nil {
+			continue
+		}
+		if p.ppid, err = strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		if p.pgid, err = strconv.Atoi(fields[2]); err != nil {
+			continue
+		}
+		p.zombie = strings.HasPrefix(fields[3], "Z")
+		procs = append(procs, p)
+	}
+	return procs
+}
+
+// descendants returns every process below pid in the process tree.
+func descendants(procs []process, pid int) []process {
+	children := make(map[int][]process)
+	for _, p := range procs {
+		children[p.ppid] = append(children[p.ppid], p)
+	}
+	var found []process
+	queue := []int{pid}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, child := range children[next] {
+			found = append(found, child)
+			queue = append(queue, child.pid)
+		}
+	}
+	return found
+}
@@ -0,0 +1,129 @@
+package tmux
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+// markerPrefix starts every line devlog writes into a pane's output.
+const markerPrefix = "[devlog]"
+
+// Run runs c.Command, then relaunches it according to c.Restart, doubling
+// the delay after each restart up to config.MaxRestartBackoff. Every exit of
+// a supervised command is announced on w, the pane, with a marker line that
+// pipe-pane copies into the pane's log:
+//
+//	[devlog] 2026-01-02T15:04:05Z api exited with code 1, restarting in 2s (restart 1/5)
+//
+// Every run's start and exit is recorded in c.Events. A run ended by SIGINT,
+// SIGTERM or SIGHUP (Ctrl+C in the pane, devlog down) is not restarted, and
+// Ctrl+C during the restart delay stops the loop too. The same signals sent
+// to devlog run itself are passed on to the command; Run reports whether it
+// got SIGTERM or SIGHUP, i.e. the pane is going away.
+func (c PaneCommand) Run(w io.Writer) (terminated bool) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	maxDelay := config.MaxRestartBackoff
+	delay := min(c.Backoff, maxDelay)
+	stopped := false
+	handle := func(sig os.Signal) {
+		stopped = true
+		terminated = terminated || sig != syscall.SIGINT
+	}
+
+	for n := 0; ; {
+		args := shellArgs(c.Command)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, w, w
+		cmd.Env = os.Environ()
+		for name, value := range c.Env {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+		foreground(cmd)
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintf(w, "%s %s failed to start: %v\n", markerPrefix, c.Label, err)
+			return terminated
+		}
+		c.record(w, PaneEvent{Event: EventStart})
+
+		waited := make(chan struct{})
+		go func() {
+			cmd.Wait()
+			close(waited)
+		}()
+	wait:
+		for {
+			select {
+			case sig := <-signals:
+				handle(sig)
+				signalGroup(cmd.Process.Pid, sig.(syscall.Signal))
+			case <-waited:
+				break wait
+			}
+		}
+		takeTerminal()
+
+		code, sig := exitStatus(cmd.ProcessState)
+		c.record(w, PaneEvent{Event: EventExit, Code: &code, Signal: int(sig)})
+		stopped = stopped || sig == syscall.SIGINT || sig == syscall.SIGTERM || sig == syscall.SIGHUP
+		if stopped || c.Restart == "" || c.Restart == config.RestartNever {
+			return terminated
+		}
+		if c.Restart == config.RestartOnFailure && code == 0 {
+			c.marker(w, "exited with code 0, not restarting")
+			return terminated
+		}
+		if c.MaxRetries > 0 && n >= c.MaxRetries {
+			c.marker(w, fmt.Sprintf("exited with code %d, giving up after %d restarts", code, n))
+			return terminated
+		}
+
+		n++
+		limit := ""
+		if c.MaxRetries > 0 {
+			limit = fmt.Sprintf("/%d", c.MaxRetries)
+		}
+		c.marker(w, fmt.Sprintf("exited with code %d, restarting in %s (restart %d%s)", code, formatDelay(delay), n, limit))
+		select {
+		case sig := <-signals:
+			handle(sig)
+			return terminated
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// formatDelay formats d like "2s", or like "500ms" below a whole second.
+func formatDelay(d time.Duration) string {
+	if d%time.Second == 0 {
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return d.String()
+}
+
+// marker writes a marker line about the pane's command to w.
+func (c PaneCommand) marker(w io.Writer, message string) {
+	fmt.Fprintf(w, "%s %s %s %s\n", markerPrefix, time.Now().UTC().Format(time.RFC3339), c.Label, message)
+}
+
+// record appends e, for the pane devlog run runs in ($TMUX_PANE), to
+// c.Events. A failure is reported on w but doesn't stop the command.
+func (c PaneCommand) record(w io.Writer, e PaneEvent) {
+	if c.Events == "" {
+		return
+	}
+	e.Pane, e.Name, e.Time = os.Getenv("TMUX_PANE"), c.Name, time.Now().UTC().Truncate(time.Millisecond)
+	if err := appendPaneEvent(c.Events, e); err != nil {
+		fmt.Fprintf(w, "%s %s: failed to record %s event: %v\n", markerPrefix, c.Label, e.Event, err)
+	}
+}
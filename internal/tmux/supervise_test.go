@@ -0,0 +1,122 @@
+package tmux
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jellydn/devlog/internal/config"
+)
+
+// runSupervised runs a pane command with a restart policy and returns its
+// output.
+func runSupervised(t *testing.T, c PaneCommand) string {
+	t.Helper()
+	c.Label = "api"
+	c.Env = map[string]string{"DEVLOG_TEST_VAR": "x"}
+	c.Events = filepath.Join(t.TempDir(), PaneEventsFile)
+	var out strings.Builder
+	if c.Run(&out) {
+		t.Error("Run() reported termination")
+	}
+	return out.String()
+}
+
+var timestampRegex = regexp.MustCompile(`\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ`)
+
+func TestPaneCommand_RunOnFailure(t *testing.T) {
+	out := runSupervised(t, PaneCommand{
+		Command:    `echo "run $DEVLOG_TEST_VAR"; exit 3`,
+		Restart:    config.RestartOnFailure,
+		MaxRetries: 1,
+		Backoff:    100 * time.Millisecond,
+	})
+	out = timestampRegex.ReplaceAllString(out, "TS")
+
+	want := "run x\n" +
+		"[devlog] TS api exited with code 3, restarting in 100ms (restart 1/1)\n" +
+		"run x\n" +
+		"[devlog] TS api exited with code 3, giving up after 1 restarts\n"
+	if out != want {
+		t.Errorf("output =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestPaneCommand_RunOnFailureCleanExit(t *testing.T) {
+	out := runSupervised(t, PaneCommand{Command: "echo done", Restart: config.RestartOnFailure, Backoff: time.Second})
+	if !strings.Contains(out, "api exited with code 0, not restarting") || strings.Count(out, "done\n") != 1 {
+		t.Errorf("output = %q, want a single run and a not-restarting marker", out)
+	}
+}
+
+func TestPaneCommand_RunAlways(t *testing.T) {
+	out := runSupervised(t, PaneCommand{Command: "echo run", Restart: config.RestartAlways, MaxRetries: 1, Backoff: 10 * time.Millisecond})
+	if strings.Count(out, "run\n") != 2 || !strings.Contains(out, "api exited with code 0, restarting in 10ms (restart 1/1)") {
+		t.Errorf("output = %q, want a restart after a clean exit", out)
+	}
+}
+
+func TestPaneCommand_RunNever(t *testing.T) {
+	out := runSupervised(t, PaneCommand{Command: "echo once; exit 1"})
+	if out != "once\n" {
+		t.Errorf("output = %q, want a single run without markers", out)
+	}
+}
+
+func TestPaneCommand_RunStopsOnInterrupt(t *testing.T) {
+	// The command interrupts itself, as Ctrl+C in the pane would
+	out := runSupervised(t, PaneCommand{Command: "echo run; kill -INT $$", Restart: config.RestartAlways, Backoff: 10 * time.Millisecond})
+	if out != "run\n" {
+		t.Errorf("output = %q, want no restart after SIGINT", out)
+	}
+}
+
+func TestPaneCommand_RunRecordsEvents(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%1")
+	dir := t.TempDir()
+	c := PaneCommand{Command: "exit 3", Name: "api", Restart: config.RestartOnFailure, MaxRetries: 1, Backoff: 10 * time.Millisecond}
+	c.Events = filepath.Join(dir, PaneEventsFile)
+	c.Run(&strings.Builder{})
+
+	events, err := ReadPaneEvents(dir)
+	if err != nil {
+		t.Fatalf("ReadPaneEvents() error = %v", err)
+	}
+	var kinds []string
+	for _, e := range events {
+		kinds = append(kinds, e.Event)
+	}
+	if strings.Join(kinds, ",") != "start,exit,start,exit" || *events[3].Code != 3 {
+		t.Errorf("events = %v, want two runs exiting with code 3", kinds)
+	}
+}
+
+func TestPaneCommand_RunPassesOnTermination(t *testing.T) {
+	t.Setenv("TMUX_PANE", "%1")
+	dir := t.TempDir()
+	c := PaneCommand{Command: "sleep 30", Restart: config.RestartAlways, Events: filepath.Join(dir, PaneEventsFile)}
+	done := make(chan bool)
+	go func() { done <- c.Run(&strings.Builder{}) }()
+
+	// Run handles signals once the command has started
+	if !waitFor(5*time.Second, func() bool {
+		events, _ := ReadPaneEvents(dir)
+		return len(events) > 0
+	}) {
+		t.Fatal("command did not start")
+	}
+	self, _ := os.FindProcess(os.Getpid())
+	self.Signal(syscall.SIGTERM)
+	select {
+	case terminated := <-done:
+		if !terminated {
+			t.Error("Run() = false, want it to report SIGTERM")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not pass SIGTERM on to the command")
+	}
+}
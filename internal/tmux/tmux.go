@@ -2,20 +2,22 @@ package tmux
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
+	"io"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jellydn/devlog/internal/config"
-	"github.com/jellydn/devlog/internal/shellescape"
 )
 
+// paneNameOption is the tmux user option that stores a pane's configured name.
+const paneNameOption = "@devlog_pane"
+
 // Runner handles tmux session operations
 type Runner struct {
 	sessionName string
+	server      Server
 	logsDir     string // resolved logs directory for this session (set by CreateSession)
 }
 
@@ -23,240 +25,135 @@ type Runner struct {
 // Window/pane types remain in internal/config (Option A: keep dependency direction).
 type SessionConfig struct {
 	Session string
-	LogsDir string // base logs directory (e.g. "./logs")
-	RunMode string // "timestamped" or "overwrite"
+	LogsDir string              // base logs directory (e.g. "./logs")
+	RunMode string              // "timestamped" or "overwrite"
+	RunDir  string              // run directory chosen by the caller; derived from LogsDir and RunMode when empty
+	Profile string              // active profile name, recorded as DEVLOG_PROFILE (optional)
+	Devlog  string              // devlog binary that runs pane commands (devlog run) and piped pane logs (devlog pipe); default "devlog"
+	Redact  config.RedactConfig // secrets masked in every pane log
 	Windows []config.WindowConfig
+
+	// Progress receives readiness progress lines (waiting/ready). Optional.
+	Progress io.Writer
 }
 
-// NewRunner creates a new tmux runner for the given session
+// NewRunner creates a new tmux runner for the given session on the user's
+// default tmux server
 func NewRunner(sessionName string) *Runner {
 	return &Runner{sessionName: sessionName}
 }
 
+// NewServerRunner creates a tmux runner for a session on a specific server
+func NewServerRunner(sessionName string, server Server) *Runner {
+	return &Runner{sessionName: sessionName, server: server}
+}
+
 // SessionExists checks if the tmux session already exists
 func (r *Runner) SessionExists() bool {
-	cmd := exec.Command("tmux", "has-session", "-t", r.sessionName)
+	cmd := r.server.Command("has-session", "-t", r.sessionName)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	err := cmd.Run()
 	return err == nil
 }
 
+// RunDir returns the directory a run's logs go to: a timestamped
+// subdirectory of logsDir in "timestamped" mode, logsDir itself otherwise.
+func RunDir(logsDir, runMode string, now time.Time) string {
+	if runMode == "timestamped" {
+		return filepath.Join(logsDir, now.Format("20060102-150405"))
+	}
+	return logsDir
+}
+
 // CreateSession creates a new tmux session with the given windows and panes.
 // It resolves the logs directory from cfg (timestamped subdirectory when needed),
 // stores it on the Runner, and exports DEVLOG_LOGS_DIR in the tmux session env.
 func (r *Runner) CreateSession(cfg SessionConfig) error {
-	if r.SessionExists() {
-		return fmt.Errorf("tmux session '%s' already exists", r.sessionName)
-	}
-
-	logsDir := cfg.LogsDir
-	if cfg.RunMode == "timestamped" {
-		logsDir = filepath.Join(cfg.LogsDir, time.Now().Format("20060102-150405"))
-	}
-	r.logsDir = logsDir
-
-	if err := os.MkdirAll(logsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create logs directory: %w", err)
-	}
-	if err := ensurePaneLogFiles(logsDir, cfg.Windows); err != nil {
-		return err
-	}
-
-	if len(cfg.Windows) == 0 || len(cfg.Windows[0].Panes) == 0 {
-		return fmt.Errorf("at least one window with one pane is required")
-	}
-
-	firstWindow := cfg.Windows[0]
-	firstPane := firstWindow.Panes[0]
-
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", r.sessionName, "-n", firstWindow.Name)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to create tmux session: %w", err)
-	}
-
-	// Store logs directory as a tmux session environment variable for later retrieval
-	// Convert to absolute path to ensure consistent resolution from any working directory
-	absLogsDir, err := filepath.Abs(logsDir)
+	plan, err := r.Plan(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to resolve absolute path for logs dir: %w", err)
-	}
-	setEnv := exec.Command("tmux", "set-environment", "-t", r.sessionName, "DEVLOG_LOGS_DIR", absLogsDir)
-	if err := setEnv.Run(); err != nil {
-		return fmt.Errorf("failed to set logs dir env: %w", err)
-	}
-
-	// Use window name in target - tmux will target the active pane in that window
-	firstWindowTarget := fmt.Sprintf("%s:%s", r.sessionName, firstWindow.Name)
-	if err := r.sendCommandWithLogging(firstWindowTarget, firstPane.Cmd, firstPane.Log); err != nil {
-		return fmt.Errorf("failed to run command in first pane: %w", err)
-	}
-
-	for i := 1; i < len(firstWindow.Panes); i++ {
-		pane := firstWindow.Panes[i]
-		if err := r.splitWindow(firstWindowTarget, pane.Cmd, pane.Log); err != nil {
-			return fmt.Errorf("failed to create pane %d in window %s: %w", i, firstWindow.Name, err)
-		}
-	}
-
-	for i := 1; i < len(cfg.Windows); i++ {
-		window := cfg.Windows[i]
-		if err := r.createWindow(i, window); err != nil {
-			return fmt.Errorf("failed to create window %s: %w", window.Name, err)
-		}
+		return err
 	}
-
-	return nil
+	return r.Execute(plan, cfg.Progress)
 }
 
-func ensurePaneLogFiles(logsDir string, windows []config.WindowConfig) error {
+// paneLogFiles lists the distinct pane log paths in logsDir, raw copies
+// included.
+func paneLogFiles(logsDir string, windows []config.WindowConfig) []string {
 	seen := make(map[string]struct{})
+	var paths []string
 	for _, window := range windows {
 		for _, pane := range window.Panes {
 			if pane.Log == "" {
 				continue
 			}
-
-			logPath := filepath.Join(logsDir, pane.Log)
-			if _, ok := seen[logPath]; ok {
-				continue
-			}
-			seen[logPath] = struct{}{}
-
-			logDir := filepath.Dir(logPath)
-			if err := os.MkdirAll(logDir, 0755); err != nil {
-				return fmt.Errorf("failed to create log directory '%s': %w", logDir, err)
-			}
-
-			f, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
-			if err != nil {
-				return fmt.Errorf("failed to create log file '%s': %w", logPath, err)
-			}
-			if err := f.Close(); err != nil {
-				return fmt.Errorf("failed to close log file '%s': %w", logPath, err)
+			for _, name := range []string{pane.Log, pane.RawLog} {
+				if name == "" {
+					continue
+				}
+				logPath := config.RunFilePath(logsDir, name)
+				if _, ok := seen[logPath]; ok {
+					continue
+				}
+				seen[logPath] = struct{}{}
+				paths = append(paths, logPath)
 			}
 		}
 	}
-	return nil
+	return paths
 }
 
-// createWindow creates a new window with its panes
-func (r *Runner) createWindow(windowIndex int, window config.WindowConfig) error {
-	cmd := exec.Command("tmux", "new-window", "-t", r.sessionName, "-n", window.Name)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to create window: %w", err)
+// sendCommand starts the pane's command over the control client, and then
+// its log. Both go in one command list, which tmux runs without reading pane
+// output in between: the log gets everything the command writes, and nothing
+// from the shell the pane was created with.
+func sendCommand(client *controlClient, slot paneSlot) error {
+	commands := [][]string{startArgs(slot)}
+	if pipe := pipeArgs(slot); pipe != nil {
+		commands = append(commands, pipe)
 	}
-
-	// Target the window by name - tmux will use the active pane
-	firstPane := window.Panes[0]
-	windowTarget := fmt.Sprintf("%s:%s", r.sessionName, window.Name)
-	if err := r.sendCommandWithLogging(windowTarget, firstPane.Cmd, firstPane.Log); err != nil {
-		return fmt.Errorf("failed to run command in first pane: %w", err)
+	if _, err := client.RunList(commands...); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
 	}
-
-	for i := 1; i < len(window.Panes); i++ {
-		pane := window.Panes[i]
-		if err := r.splitWindow(windowTarget, pane.Cmd, pane.Log); err != nil {
-			return fmt.Errorf("failed to create pane %d: %w", i, err)
-		}
-	}
-
 	return nil
 }
 
-// splitWindow splits the current window and runs a command with logging
-func (r *Runner) splitWindow(target string, command, logFile string) error {
-	cmd := exec.Command("tmux", "split-window", "-h", "-t", target)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to split window: %w", err)
-	}
-
-	// After split-window, the new pane is active, so we can send to the window
-	if err := r.sendCommandWithLogging(target, command, logFile); err != nil {
-		return err
-	}
-
-	return nil
+// startArgs replaces the pane's shell with `devlog run` for its command, with
+// the merged window/pane environment (see PaneCommand). Nothing is typed into
+// the pane, so neither the pane nor its log show the command line.
+func startArgs(slot paneSlot) []string {
+	args := []string{"respawn-pane", "-k", "-t", slot.id, slot.devlog, RunCommand}
+	return append(args, newPaneCommand(slot).Args()...)
 }
 
-// sendCommandWithLogging sends a command to a pane with output captured via pipe-pane
-func (r *Runner) sendCommandWithLogging(target, command, logFile string) error {
-	if logFile != "" {
-		logPath := filepath.Join(r.logsDir, logFile)
-
-		// Quote the path to prevent command injection
-		pipeCmd := fmt.Sprintf("cat >> %s", shellescape.Quote(logPath))
-		cmd := exec.Command("tmux", "pipe-pane", "-t", target, "-o", pipeCmd)
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("failed to set up pipe-pane logging: %w", err)
-		}
+// pipeArgs returns the pipe-pane arguments that copy the pane's output to its
+// log, or nil when the pane has no log.
+func pipeArgs(slot paneSlot) []string {
+	if slot.pipe == "" {
+		return nil
 	}
-
-	// Run pane commands through POSIX sh so bash-style syntax works even when the
-	// user's interactive shell is fish/zsh.
-	shCommand := fmt.Sprintf("sh -lc %s", shellescape.Quote(command))
-	cmd := exec.Command("tmux", "send-keys", "-t", target, shCommand, "C-m")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to send command: %w", err)
-	}
-
-	return nil
+	return []string{"pipe-pane", "-t", slot.id, "-o", slot.pipe}
 }
 
-// KillSession gracefully terminates all panes and kills the tmux session
-func (r *Runner) KillSession() error {
-	if !r.SessionExists() {
-		return fmt.Errorf("tmux session '%s' does not exist", r.sessionName)
-	}
-
-	paneIDs, err := r.getPaneIDs()
-	if err != nil {
-		return fmt.Errorf("failed to get pane list: %w", err)
+// splitArgs returns the split-window direction and size flags for pane:
+// side by side (-h) unless split is "v", sized as a percentage when set.
+func splitArgs(pane config.PaneConfig) []string {
+	args := []string{"-h"}
+	if pane.Split == config.SplitVertical {
+		args = []string{"-v"}
 	}
-
-	// Send Ctrl+C to all panes to gracefully terminate processes
-	for _, paneID := range paneIDs {
-		target := fmt.Sprintf("%s:%s", r.sessionName, paneID)
-		cmd := exec.Command("tmux", "send-keys", "-t", target, "C-c")
-		cmd.Run() // Ignore errors - pane might not have a process
+	if pane.Size > 0 {
+		args = append(args, "-l", fmt.Sprintf("%d%%", pane.Size))
 	}
-
-	// Wait in the Go process so the grace period is deterministic.
-	// (send-keys "sleep 0.5" runs inside a pane shell and does not block KillSession.)
-	time.Sleep(500 * time.Millisecond)
-
-	// Force kill any remaining processes with C-c again
-	for _, paneID := range paneIDs {
-		target := fmt.Sprintf("%s:%s", r.sessionName, paneID)
-		cmd := exec.Command("tmux", "send-keys", "-t", target, "C-c")
-		cmd.Run()
-	}
-
-	// Kill the session (this will close all panes and flush logs)
-	cmd := exec.Command("tmux", "kill-session", "-t", r.sessionName)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to kill tmux session: %w", err)
-	}
-
-	return nil
+	return args
 }
 
-// getPaneIDs returns all pane IDs in the session
-func (r *Runner) getPaneIDs() ([]string, error) {
-	cmd := exec.Command("tmux", "list-panes", "-t", r.sessionName, "-F", "#{pane_id}")
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var ids []string
-	for _, line := range lines {
-		if line != "" {
-			ids = append(ids, line)
-		}
+// appendCwdArg adds tmux's -c start-directory flag when cwd is set.
+func appendCwdArg(args []string, cwd string) []string {
+	if cwd == "" {
+		return args
 	}
-	return ids, nil
+	return append(args, "-c", cwd)
 }
 
 // GetLogsDir returns the resolved logs directory for this session.
@@ -266,7 +163,18 @@ func (r *Runner) GetLogsDir() string {
 	if r.logsDir != "" {
 		return r.logsDir
 	}
-	cmd := exec.Command("tmux", "show-environment", "-t", r.sessionName, "DEVLOG_LOGS_DIR")
+	return r.sessionEnv("DEVLOG_LOGS_DIR")
+}
+
+// GetProfile returns the profile the running session was started with
+// (the DEVLOG_PROFILE session env), or "" when none was used.
+func (r *Runner) GetProfile() string {
+	return r.sessionEnv("DEVLOG_PROFILE")
+}
+
+// sessionEnv reads a variable from the tmux session environment.
+func (r *Runner) sessionEnv(name string) string {
+	cmd := r.server.Command("show-environment", "-t", r.sessionName, name)
 	output, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -288,10 +196,15 @@ func (r *Runner) GetSessionInfo() (*SessionInfo, error) {
 		Name:    r.sessionName,
 		Windows: []WindowInfo{},
 	}
+	if output, err := r.server.Command("display-message", "-p", "-t", r.sessionName, "#{session_created}").Output(); err == nil {
+		if created, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+			info.Created = time.Unix(created, 0)
+		}
+	}
 
 	// Use tabs as field separators — window/pane names can contain '|' but not tabs
 	// in tmux -F output.
-	cmd := exec.Command("tmux", "list-windows", "-t", r.sessionName, "-F", "#{window_index}\t#{window_name}\t#{window_panes}")
+	cmd := r.server.Command("list-windows", "-t", r.sessionName, "-F", "#{window_index}\t#{window_name}\t#{window_panes}")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list windows: %w", err)
@@ -317,7 +230,7 @@ func (r *Runner) GetSessionInfo() (*SessionInfo, error) {
 // getWindowPanes returns information about all panes in a window
 func (r *Runner) getWindowPanes(windowIndex int) ([]PaneInfo, error) {
 	windowTarget := fmt.Sprintf("%s:%d", r.sessionName, windowIndex)
-	cmd := exec.Command("tmux", "list-panes", "-t", windowTarget, "-F", "#{pane_id}\t#{pane_index}\t#{pane_current_command}")
+	cmd := r.server.Command("list-panes", "-t", windowTarget, "-F", "#{pane_id}\t#{pane_index}\t#{pane_current_command}\t#{"+paneNameOption+"}")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
@@ -361,16 +274,19 @@ func parseWindowLine(line string) (WindowInfo, bool) {
 }
 
 // parsePaneLine parses a tab-delimited tmux list-panes -F line:
-// id\tindex\tcommand
+// id\tindex\tcommand[\tname]
 func parsePaneLine(line string) (PaneInfo, bool) {
 	var pane PaneInfo
 	if line == "" {
 		return pane, false
 	}
 	parts := strings.Split(line, "\t")
-	if len(parts) != 3 {
+	if len(parts) != 3 && len(parts) != 4 {
 		return pane, false
 	}
+	if len(parts) == 4 {
+		pane.Name = parts[3]
+	}
 	index, err := strconv.Atoi(parts[1])
 	if err != nil {
 		return pane, false
@@ -384,6 +300,7 @@ func parsePaneLine(line string) (PaneInfo, bool) {
 // SessionInfo holds information about a tmux session
 type SessionInfo struct {
 	Name    string
+	Created time.Time
 	Windows []WindowInfo
 }
 
@@ -399,12 +316,13 @@ type WindowInfo struct {
 type PaneInfo struct {
 	ID      string
 	Index   int
+	Name    string // configured pane name, empty when unnamed
 	Command string
 }
 
 // CheckVersion returns the tmux version string or an error if tmux is not installed
 func CheckVersion() (string, error) {
-	cmd := exec.Command("tmux", "-V")
+	cmd := Command("-V")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("tmux not found")
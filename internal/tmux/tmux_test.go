@@ -1,24 +1,98 @@
 package tmux
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"fmt"
 	"github.com/jellydn/devlog/internal/config"
+	"github.com/jellydn/devlog/internal/panelog"
+	"github.com/jellydn/devlog/internal/shellescape"
 	"time"
 )
 
-func TestRunner_SessionExists(t *testing.T) {
-	// Check if tmux is available
+// TestMain lets the test binary stand in for devlog in panes: sessions the
+// tests create start pane commands with `<test binary> run ...`.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == RunCommand {
+		if err := RunPane(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	if path, err := os.Executable(); err == nil {
+		defaultDevlog = path
+	}
+	os.Exit(m.Run())
+}
+
+// testServer returns a tmux server of the test's own, killed when the test
+// ends, so tests neither share a server with each other nor touch the user's.
+// It skips the test when tmux is not installed.
+func testServer(t *testing.T) Server {
+	t.Helper()
 	if _, err := exec.LookPath("tmux"); err != nil {
 		t.Skip("tmux not available in PATH")
 	}
+	server := Server{Socket: fmt.Sprintf("devlog-test-%d", time.Now().UnixNano())}
+	t.Cleanup(func() {
+		server.Command("kill-server").Run()
+		// tmux leaves the socket file behind
+		dir := os.Getenv("TMUX_TMPDIR")
+		if dir == "" {
+			dir = "/tmp"
+		}
+		os.Remove(filepath.Join(dir, fmt.Sprintf("tmux-%d", os.Getuid()), server.Socket))
+	})
+	return server
+}
+
+// paneStartTimeout bounds how long tests wait for a pane's command to run.
+// The pane's login shell may take seconds to start on a loaded machine.
+const paneStartTimeout = 30 * time.Second
 
-	runner := NewRunner("test-exists-session")
+// waitForStarts waits until n pane commands have started in runDir, as
+// their start events tell, and fails the test if they don't.
+func waitForStarts(t *testing.T, runDir string, n int) {
+	t.Helper()
+	var events []PaneEvent
+	if !waitFor(paneStartTimeout, func() bool {
+		events, _ = ReadPaneEvents(runDir)
+		starts := 0
+		for _, e := range events {
+			if e.Event == EventStart {
+				starts++
+			}
+		}
+		return starts >= n
+	}) {
+		t.Fatalf("pane events = %+v, want %d commands started", events, n)
+	}
+}
+
+// waitFor polls cond until it holds or timeout passes, and reports whether it
+// held.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return true
+}
+
+func TestRunner_SessionExists(t *testing.T) {
+	server := testServer(t)
+	runner := NewServerRunner("test-exists-session", server)
 
 	// Should not exist initially
 	if runner.SessionExists() {
@@ -26,11 +100,10 @@ func TestRunner_SessionExists(t *testing.T) {
 	}
 
 	// Create a test session
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", "test-exists-session")
+	cmd := server.Command("new-session", "-d", "-s", "test-exists-session")
 	if err := cmd.Run(); err != nil {
 		t.Skipf("Could not create tmux session: %v", err)
 	}
-	defer exec.Command("tmux", "kill-session", "-t", "test-exists-session").Run()
 
 	// Should exist now
 	if !runner.SessionExists() {
@@ -39,19 +112,14 @@ func TestRunner_SessionExists(t *testing.T) {
 }
 
 func TestRunner_CreateSession_AlreadyExists(t *testing.T) {
-	// Check if tmux is available
-	if _, err := exec.LookPath("tmux"); err != nil {
-		t.Skip("tmux not available in PATH")
-	}
-
-	runner := NewRunner("test-duplicate-session")
+	server := testServer(t)
+	runner := NewServerRunner("test-duplicate-session", server)
 
 	// Create a test session
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", "test-duplicate-session")
+	cmd := server.Command("new-session", "-d", "-s", "test-duplicate-session")
 	if err := cmd.Run(); err != nil {
 		t.Skipf("Could not create tmux session: %v", err)
 	}
-	defer exec.Command("tmux", "kill-session", "-t", "test-duplicate-session").Run()
 
 	// Try to create again - should fail
 	err := runner.CreateSession(SessionConfig{
@@ -76,12 +144,7 @@ func TestRunner_CreateSession_AlreadyExists(t *testing.T) {
 }
 
 func TestRunner_KillSession_NotExists(t *testing.T) {
-	// Check if tmux is available
-	if _, err := exec.LookPath("tmux"); err != nil {
-		t.Skip("tmux not available in PATH")
-	}
-
-	runner := NewRunner("test-nonexistent-session")
+	runner := NewServerRunner("test-nonexistent-session", testServer(t))
 
 	err := runner.KillSession()
 	if err == nil {
@@ -93,30 +156,22 @@ func TestRunner_KillSession_NotExists(t *testing.T) {
 }
 
 func TestRunner_CreateAndKillSession(t *testing.T) {
-	// Check if tmux is available
-	if _, err := exec.LookPath("tmux"); err != nil {
-		t.Skip("tmux not available in PATH")
-	}
-
 	sessionName := "test-create-kill-session"
-	runner := NewRunner(sessionName)
-
-	// Clean up any existing session
-	exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+	runner := NewServerRunner(sessionName, testServer(t))
 
 	logsDir := t.TempDir()
 	windows := []config.WindowConfig{
 		{
 			Name: "main",
 			Panes: []config.PaneConfig{
-				{Cmd: "echo 'pane 1'", Log: "pane1.log"},
-				{Cmd: "echo 'pane 2'", Log: "pane2.log"},
+				{Cmd: "echo 'pane 1'; sleep 60", Log: "pane1.log"},
+				{Cmd: "echo 'pane 2'; sleep 60", Log: "pane2.log"},
 			},
 		},
 		{
 			Name: "secondary",
 			Panes: []config.PaneConfig{
-				{Cmd: "echo 'pane 3'", Log: "pane3.log"},
+				{Cmd: "echo 'pane 3'; sleep 60", Log: "pane3.log"},
 			},
 		},
 	}
@@ -130,6 +185,7 @@ func TestRunner_CreateAndKillSession(t *testing.T) {
 	if !runner.SessionExists() {
 		t.Error("Session should exist after creation")
 	}
+	waitForStarts(t, logsDir, 3)
 
 	// Get session info
 	info, err := runner.GetSessionInfo()
@@ -181,12 +237,7 @@ func TestRunner_CreateAndKillSession(t *testing.T) {
 }
 
 func TestRunner_GetSessionInfo_NotExists(t *testing.T) {
-	// Check if tmux is available
-	if _, err := exec.LookPath("tmux"); err != nil {
-		t.Skip("tmux not available in PATH")
-	}
-
-	runner := NewRunner("test-getinfo-nonexistent")
+	runner := NewServerRunner("test-getinfo-nonexistent", testServer(t))
 
 	_, err := runner.GetSessionInfo()
 	if err == nil {
@@ -211,16 +262,8 @@ func TestRunner_CreateSession_NoWindows(t *testing.T) {
 }
 
 func TestRunner_CreateSession_CreatesLogsDir(t *testing.T) {
-	// Check if tmux is available
-	if _, err := exec.LookPath("tmux"); err != nil {
-		t.Skip("tmux not available in PATH")
-	}
-
 	sessionName := "test-logs-dir-creation"
-	runner := NewRunner(sessionName)
-
-	// Clean up any existing session
-	exec.Command("tmux", "kill-session", "-t", sessionName).Run()
+	runner := NewServerRunner(sessionName, testServer(t))
 
 	logsDir := t.TempDir() + "/nested/logs/dir"
 	windows := []config.WindowConfig{
@@ -241,9 +284,6 @@ func TestRunner_CreateSession_CreatesLogsDir(t *testing.T) {
 	if _, err := os.Stat(logsDir); os.IsNotExist(err) {
 		t.Error("Logs directory was not created")
 	}
-
-	// Clean up
-	runner.KillSession()
 }
 
 func TestCheckVersion(t *testing.T) {
@@ -263,7 +303,7 @@ func TestCheckVersion(t *testing.T) {
 	}
 }
 
-func TestEnsurePaneLogFiles(t *testing.T) {
+func TestCreatePaneLogFiles(t *testing.T) {
 	logsDir := t.TempDir()
 	windows := []config.WindowConfig{
 		{
@@ -277,8 +317,15 @@ func TestEnsurePaneLogFiles(t *testing.T) {
 		},
 	}
 
-	if err := ensurePaneLogFiles(logsDir, windows); err != nil {
-		t.Fatalf("ensurePaneLogFiles() failed: %v", err)
+	paths := paneLogFiles(logsDir, windows)
+	if len(paths) != 2 {
+		t.Fatalf("paneLogFiles() = %v, want 2 distinct paths", paths)
+	}
+	plan := &Plan{}
+	for _, path := range paths {
+		if err := plan.createFile(path); err != nil {
+			t.Fatalf("createFile() failed: %v", err)
+		}
 	}
 
 	logPaths := []string{
@@ -342,14 +389,8 @@ func TestParsePaneLine_CommandWithPipe(t *testing.T) {
 }
 
 func TestRunner_CreateSession_TimestampedLogsDir(t *testing.T) {
-	if _, err := exec.LookPath("tmux"); err != nil {
-		t.Skip("tmux not available in PATH")
-	}
-
 	base := t.TempDir()
-	session := fmt.Sprintf("test-ts-%d", time.Now().UnixNano()%100000)
-	runner := NewRunner(session)
-	defer exec.Command("tmux", "kill-session", "-t", session).Run()
+	runner := NewServerRunner("test-ts", testServer(t))
 
 	windows := []config.WindowConfig{
 		{
@@ -367,6 +408,10 @@ func TestRunner_CreateSession_TimestampedLogsDir(t *testing.T) {
 		t.Fatalf("CreateSession() failed: %v", err)
 	}
 
+	if profile := runner.GetProfile(); profile != "" {
+		t.Errorf("GetProfile() = %q, want empty when no profile is set", profile)
+	}
+
 	got := runner.GetLogsDir()
 	if got == base {
 		t.Fatalf("GetLogsDir() = base dir %q, want timestamped subdirectory", got)
@@ -378,3 +423,688 @@ func TestRunner_CreateSession_TimestampedLogsDir(t *testing.T) {
 		t.Fatalf("resolved logs dir missing: %v", err)
 	}
 }
+
+func TestParsePaneLine_WithName(t *testing.T) {
+	pane, ok := parsePaneLine("%3\t0\tnode\tapi")
+	if !ok {
+		t.Fatal("parsePaneLine returned false")
+	}
+	if pane.Name != "api" {
+		t.Errorf("Name = %q, want %q", pane.Name, "api")
+	}
+	if pane.Command != "node" {
+		t.Errorf("Command = %q, want %q", pane.Command, "node")
+	}
+}
+
+func TestShellArgs(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"npm run dev", "exec npm run dev"},
+		{`./server --name "a b" > out.log`, `exec ./server --name "a b" > out.log`},
+		{"make db && make api", "make db && make api"},
+		{"echo $(date)", "echo $(date)"},
+		{"PORT=3000 npm start", "PORT=3000 npm start"},
+		{"cd web", "cd web"},
+		{"exit 3", "exit 3"},
+	}
+	for _, tt := range tests {
+		got := shellArgs(tt.command)
+		if len(got) != 3 || got[0] != "sh" || got[1] != "-lc" || got[2] != tt.want {
+			t.Errorf("shellArgs(%q) = %q, want sh -lc %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestPaneCommand_Args(t *testing.T) {
+	c := PaneCommand{
+		Command:    "npm run dev -- --port 3000",
+		Env:        map[string]string{"PORT": "3000", "API_URL": "http://x/?a=b"},
+		Name:       "web",
+		Label:      "web",
+		Events:     "/tmp/run/" + PaneEventsFile,
+		Restart:    config.RestartOnFailure,
+		MaxRetries: 5,
+		Backoff:    2 * time.Second,
+	}
+	args := c.Args()
+	want := "--events /tmp/run/pane-events.jsonl --name web --label web --env API_URL=http://x/?a=b --env PORT=3000 --restart on-failure --max-retries 5 --backoff 2s -- npm run dev -- --port 3000"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+
+	parsed, err := ParsePaneCommand(args)
+	if err != nil {
+		t.Fatalf("ParsePaneCommand() error = %v", err)
+	}
+	if fmt.Sprint(parsed) != fmt.Sprint(c) {
+		t.Errorf("ParsePaneCommand(Args()) = %+v, want %+v", parsed, c)
+	}
+
+	for _, args := range [][]string{
+		{"--name", "web"},
+		{"--restart", "sometimes", "--", "true"},
+		{"--env", "PORT", "--", "true"},
+		{"--", "true", "extra"},
+	} {
+		if _, err := ParsePaneCommand(args); err == nil {
+			t.Errorf("ParsePaneCommand(%q) error = nil", args)
+		}
+	}
+}
+
+func TestRunner_CreateSession_PaneCwdEnvAndName(t *testing.T) {
+	session := "test-pane-opts"
+	runner := NewServerRunner(session, testServer(t))
+
+	logsDir := t.TempDir()
+	workDir := t.TempDir()
+	windows := []config.WindowConfig{
+		{
+			Name: "main",
+			Env:  map[string]string{"DEVLOG_WINDOW_VAR": "window"},
+			Panes: []config.PaneConfig{
+				{
+					Name: "web",
+					Cmd:  `echo "$DEVLOG_WINDOW_VAR $DEVLOG_PANE_VAR $(pwd)"; sleep 60`,
+					Log:  "web.log",
+					Cwd:  workDir,
+					Env:  map[string]string{"DEVLOG_PANE_VAR": "pane"},
+				},
+			},
+		},
+	}
+	if err := runner.CreateSession(SessionConfig{LogsDir: logsDir, RunMode: "overwrite", Profile: "web-only", Windows: windows}); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+
+	want := "window pane " + workDir
+	logPath := filepath.Join(logsDir, "web.log")
+	var data []byte
+	if !waitFor(paneStartTimeout, func() bool {
+		data, _ = os.ReadFile(logPath)
+		return strings.Contains(string(data), want)
+	}) {
+		t.Fatalf("log %s does not contain %q:\n%s", logPath, want, data)
+	}
+
+	if profile := runner.GetProfile(); profile != "web-only" {
+		t.Errorf("GetProfile() = %q, want %q", profile, "web-only")
+	}
+	info, err := runner.GetSessionInfo()
+	if err != nil {
+		t.Fatalf("GetSessionInfo() failed: %v", err)
+	}
+	if len(info.Windows) != 1 || len(info.Windows[0].Panes) != 1 {
+		t.Fatalf("unexpected session layout: %+v", info)
+	}
+	if name := info.Windows[0].Panes[0].Name; name != "web" {
+		t.Errorf("pane Name = %q, want %q", name, "web")
+	}
+}
+
+func TestRunner_CreateSession_PaneRunsCommandItself(t *testing.T) {
+	session := "test-pane-command"
+	runner := NewServerRunner(session, testServer(t))
+
+	logsDir := t.TempDir()
+	windows := []config.WindowConfig{
+		{Name: "main", Panes: []config.PaneConfig{{Name: "nap", Cmd: "sleep 61", Log: "nap.log"}}},
+	}
+	if err := runner.CreateSession(SessionConfig{LogsDir: logsDir, RunMode: "overwrite", Windows: windows}); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	waitForStarts(t, logsDir, 1)
+
+	// The pane's current command is the command, not a shell running it
+	var command string
+	if !waitFor(paneStartTimeout, func() bool {
+		info, err := runner.GetSessionInfo()
+		if err != nil || len(info.Windows) != 1 || len(info.Windows[0].Panes) != 1 {
+			return false
+		}
+		command = info.Windows[0].Panes[0].Command
+		return command == "sleep"
+	}) {
+		t.Fatalf("pane command = %q, want sleep", command)
+	}
+
+	data, err := os.ReadFile(filepath.Join(logsDir, "nap.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sleep") || strings.Contains(string(data), PaneEventsFile) {
+		t.Errorf("pane log shows how the command was started:\n%s", data)
+	}
+}
+
+func TestRunner_CreateSession_DependsOnWaitsForReady(t *testing.T) {
+	session := "test-depends"
+	runner := NewServerRunner(session, testServer(t))
+
+	logsDir := t.TempDir()
+	orderFile := filepath.Join(logsDir, "order.txt")
+	windows := []config.WindowConfig{
+		{
+			Name: "main",
+			Panes: []config.PaneConfig{
+				{
+					Name:      "api",
+					Cmd:       "echo api >> " + orderFile,
+					DependsOn: config.StringList{"db"},
+				},
+				{
+					Name:  "db",
+					Cmd:   "sleep 0.5; echo db >> " + orderFile + "; echo db-ready",
+					Log:   "db.log",
+					Ready: config.ReadyConfig{Log: "^db-ready", Timeout: "10s", Interval: "50ms"},
+				},
+			},
+		},
+	}
+	var progress strings.Builder
+	if err := runner.CreateSession(SessionConfig{LogsDir: logsDir, RunMode: "overwrite", Windows: windows, Progress: &progress}); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	if !strings.Contains(progress.String(), "Pane db is ready") {
+		t.Errorf("progress output = %q, want db ready line", progress.String())
+	}
+
+	var data []byte
+	if !waitFor(paneStartTimeout, func() bool {
+		data, _ = os.ReadFile(orderFile)
+		return string(data) == "db\napi\n"
+	}) {
+		t.Fatalf("start order = %q, want db before api", data)
+	}
+}
+
+func TestRunner_CreateSession_ReadyLogMatchesOnlyOutput(t *testing.T) {
+	session := "test-ready-own-output"
+	runner := NewServerRunner(session, testServer(t))
+
+	logsDir := t.TempDir()
+	marker := filepath.Join(logsDir, "started")
+	windows := []config.WindowConfig{
+		{
+			Name: "main",
+			Panes: []config.PaneConfig{{
+				Name: "api",
+				// The pattern is in the command line too, which must not count
+				Cmd:   "sleep 0.5; touch " + marker + "; echo listening on 8080",
+				Log:   "api.log",
+				Ready: config.ReadyConfig{Log: "listening on", Timeout: "10s", Interval: "50ms"},
+			}},
+		},
+	}
+	if err := runner.CreateSession(SessionConfig{LogsDir: logsDir, RunMode: "overwrite", Windows: windows}); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("pane was ready before its command wrote the pattern: %v", err)
+	}
+}
+
+func TestRunner_CreateSession_NotReady(t *testing.T) {
+	session := "test-not-ready"
+	runner := NewServerRunner(session, testServer(t))
+
+	windows := []config.WindowConfig{
+		{
+			Name: "main",
+			Panes: []config.PaneConfig{
+				{Name: "db", Cmd: "true", Ready: config.ReadyConfig{HTTP: "http://127.0.0.1:1/", Timeout: "300ms", Interval: "50ms"}},
+				{Name: "api", Cmd: "true", DependsOn: config.StringList{"db"}},
+				{Name: "worker", Cmd: "true", DependsOn: config.StringList{"api"}},
+			},
+		},
+	}
+	err := runner.CreateSession(SessionConfig{LogsDir: t.TempDir(), RunMode: "overwrite", Windows: windows})
+
+	var notReady *NotReadyError
+	if !errors.As(err, &notReady) {
+		t.Fatalf("CreateSession() error = %v, want *NotReadyError", err)
+	}
+	if len(notReady.NotReady) != 1 || notReady.NotReady[0].Pane != "db" {
+		t.Errorf("NotReady = %+v, want db", notReady.NotReady)
+	}
+	if !strings.Contains(notReady.NotReady[0].Reason, "not ready after 300ms") {
+		t.Errorf("NotReady reason = %q, want timeout", notReady.NotReady[0].Reason)
+	}
+	want := []PaneFailure{
+		{Pane: "api", Reason: "dependency 'db' is not ready"},
+		{Pane: "worker", Reason: "dependency 'api' is not ready"},
+	}
+	if fmt.Sprint(notReady.NotStarted) != fmt.Sprint(want) {
+		t.Errorf("NotStarted = %+v, want %+v", notReady.NotStarted, want)
+	}
+	if !runner.SessionExists() {
+		t.Error("session was killed, want it left running for inspection")
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		pane config.PaneConfig
+		want string
+	}{
+		{config.PaneConfig{}, "-h"},
+		{config.PaneConfig{Split: "h", Size: 30}, "-h -l 30%"},
+		{config.PaneConfig{Split: "v"}, "-v"},
+		{config.PaneConfig{Split: "v", Size: 25}, "-v -l 25%"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(splitArgs(tt.pane), " "); got != tt.want {
+			t.Errorf("splitArgs(%+v) = %q, want %q", tt.pane, got, tt.want)
+		}
+	}
+}
+
+func TestRunner_CreateSession_Layout(t *testing.T) {
+	session := "test-layout"
+	server := testServer(t)
+	runner := NewServerRunner(session, server)
+
+	windows := []config.WindowConfig{
+		{Name: "split", Panes: []config.PaneConfig{{Cmd: "true"}, {Cmd: "true", Split: "v"}}},
+		{Name: "stacked", Layout: "even-vertical", Panes: []config.PaneConfig{{Cmd: "true"}, {Cmd: "true"}, {Cmd: "true"}}},
+	}
+	if err := runner.CreateSession(SessionConfig{LogsDir: t.TempDir(), RunMode: "overwrite", Windows: windows}); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+
+	// Stacked panes all start at the left edge
+	for _, name := range []string{"split", "stacked"} {
+		out, err := server.Command("list-panes", "-t", session+":"+name, "-F", "#{pane_left}").Output()
+		if err != nil {
+			t.Fatalf("list-panes failed: %v", err)
+		}
+		for _, left := range strings.Fields(string(out)) {
+			if left != "0" {
+				t.Errorf("window %s pane_left = %q, want all panes stacked", name, strings.Fields(string(out)))
+				break
+			}
+		}
+	}
+}
+
+func TestCommand_Trace(t *testing.T) {
+	var buf strings.Builder
+	SetTrace(&buf)
+	defer SetTrace(nil)
+
+	Command("send-keys", "-t", "%1", "sh -lc 'npm run dev'", "C-m")
+	want := `+ tmux send-keys -t %1 'sh -lc '\''npm run dev'\''' C-m` + "\n"
+	if buf.String() != want {
+		t.Errorf("trace = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRunner_Plan(t *testing.T) {
+	logsDir := t.TempDir()
+	windows := []config.WindowConfig{
+		{
+			Name:   "main",
+			Layout: "tiled",
+			Panes: []config.PaneConfig{
+				{Name: "db", Cmd: "postgres", Ready: config.ReadyConfig{TCP: "5432"}},
+				{Name: "api", Cmd: "go run .", Log: "api.log", DependsOn: config.StringList{"db"}, Split: "v"},
+			},
+		},
+		{Name: "jobs", Panes: []config.PaneConfig{{Cmd: "make worker"}}},
+	}
+	plan, err := NewRunner("plan-test").Plan(SessionConfig{RunDir: logsDir, Profile: "full", Windows: windows})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	var got []string
+	for _, step := range plan.Steps {
+		got = append(got, traceLine(step.Args)+" "+step.Pane)
+	}
+	absLogsDir, _ := filepath.Abs(logsDir)
+	logPath := filepath.Join(logsDir, "api.log")
+	want := []string{
+		"tmux new-session -P -F '#{pane_id}' -s plan-test -n main %0.0",
+		"tmux set-environment -t plan-test DEVLOG_LOGS_DIR " + absLogsDir + " ",
+		"tmux set-environment -t plan-test DEVLOG_PROFILE full ",
+		"tmux set-option -p -t %0.0 @devlog_pane db ",
+		"tmux split-window -v -P -F '#{pane_id}' -t %0.0 %0.1",
+		"tmux set-option -p -t %0.1 @devlog_pane api ",
+		"tmux select-layout -t %0.0 tiled ",
+		"tmux new-window -P -F '#{pane_id}' -t plan-test -n jobs %1.0",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Plan() steps:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(plan.LogFiles) != 1 || plan.LogFiles[0] != logPath {
+		t.Errorf("LogFiles = %v, want [%s]", plan.LogFiles, logPath)
+	}
+	if len(plan.Starts) != 3 {
+		t.Fatalf("got %d starts, want 3", len(plan.Starts))
+	}
+	if s := plan.Starts[0]; s.Pane != "db" || s.Ready != "tcp localhost:5432" || strings.Join(s.Args[:4], " ") != "respawn-pane -k -t %0.0" {
+		t.Errorf("db start = %+v", s)
+	}
+	if s := plan.Starts[2]; !slices.Contains(s.Args, RunCommand) || s.Args[len(s.Args)-1] != "make worker" {
+		t.Errorf("jobs start = %+v, want devlog run with the command last", s)
+	}
+	if s := plan.Starts[1]; s.Pane != "api" || len(s.DependsOn) != 1 || s.Ready != "" {
+		t.Errorf("api start = %+v", s)
+	}
+	// The log is piped when the command starts, not when the pane is created
+	wantPipe := "pipe-pane -t %0.1 -o " + controlQuote("cat >> "+shellescape.Quote(logPath))
+	if got := controlLine(plan.Starts[1].Pipe); got != wantPipe {
+		t.Errorf("api pipe = %s, want %s", got, wantPipe)
+	}
+	if plan.Starts[0].Pipe != nil {
+		t.Errorf("db pipe = %q, want none without a log", plan.Starts[0].Pipe)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("Plan() must not create log files")
+	}
+}
+
+func TestRunner_CreateSession_WindowNamesNeedNoEscaping(t *testing.T) {
+	session := "test-window-names"
+	runner := NewServerRunner(session, testServer(t))
+
+	windows := []config.WindowConfig{
+		{Name: "api.v2", Layout: "even-vertical", Panes: []config.PaneConfig{{Cmd: "sleep 60"}, {Cmd: "sleep 60"}}},
+		{Name: "dup", Panes: []config.PaneConfig{{Cmd: "sleep 60"}}},
+		{Name: "dup", Panes: []config.PaneConfig{{Cmd: "sleep 60"}, {Cmd: "sleep 60"}, {Cmd: "sleep 60"}}},
+	}
+	logsDir := t.TempDir()
+	if err := runner.CreateSession(SessionConfig{LogsDir: logsDir, RunMode: "overwrite", Windows: windows}); err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+	waitForStarts(t, logsDir, 6)
+
+	info, err := runner.GetSessionInfo()
+	if err != nil {
+		t.Fatalf("GetSessionInfo() failed: %v", err)
+	}
+	var got []string
+	for _, w := range info.Windows {
+		got = append(got, fmt.Sprintf("%s:%d", w.Name, w.PaneCount))
+	}
+	if want := "api.v2:2 dup:1 dup:3"; strings.Join(got, " ") != want {
+		t.Errorf("windows = %v, want %s", got, want)
+	}
+}
+
+func TestRunner_CreateSession_RollsBackOnFailure(t *testing.T) {
+	session := "test-rollback"
+	runner := NewServerRunner(session, testServer(t))
+
+	base := t.TempDir()
+	runDir := filepath.Join(base, "logs", "run")
+	windows := []config.WindowConfig{
+		{Name: "first", Panes: []config.PaneConfig{{Cmd: "true", Log: "first.log"}}},
+		{Name: "second", Layout: "no-such-layout", Panes: []config.PaneConfig{{Cmd: "true"}, {Cmd: "true"}}},
+	}
+	err := runner.CreateSession(SessionConfig{RunDir: runDir, Windows: windows})
+	if err == nil || !strings.Contains(err.Error(), "select-layout") {
+		t.Fatalf("CreateSession() error = %v, want select-layout failure", err)
+	}
+
+	if runner.SessionExists() {
+		t.Error("partial session should have been killed")
+	}
+	if _, err := os.Stat(filepath.Join(base, "logs")); !os.IsNotExist(err) {
+		t.Errorf("logs directory should have been removed, stat err = %v", err)
+	}
+}
+
+func TestRemoveIfEmpty(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.log")
+	full := filepath.Join(dir, "full.log")
+	os.WriteFile(empty, nil, 0644)
+	os.WriteFile(full, []byte("x"), 0644)
+
+	for _, path := range []string{empty, full, dir, filepath.Join(dir, "missing")} {
+		if err := RemoveIfEmpty(path); err != nil {
+			t.Fatalf("RemoveIfEmpty(%s) error = %v", path, err)
+		}
+	}
+	if _, err := os.Stat(empty); !os.IsNotExist(err) {
+		t.Error("empty file should be removed")
+	}
+	if _, err := os.Stat(full); err != nil {
+		t.Error("non-empty file should be kept")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Error("non-empty directory should be kept")
+	}
+}
+
+func TestRemoveRunDir(t *testing.T) {
+	base := t.TempDir()
+	bookkeeping := filepath.Join(base, "bookkeeping")
+	os.Mkdir(bookkeeping, 0755)
+	os.WriteFile(filepath.Join(bookkeeping, "hooks.log"), []byte("pre_up output"), 0644)
+	os.WriteFile(filepath.Join(bookkeeping, PaneEventsFile), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(bookkeeping, "empty.log"), nil, 0644)
+	logged := filepath.Join(base, "logged")
+	os.Mkdir(logged, 0755)
+	os.WriteFile(filepath.Join(logged, "hooks.log"), []byte("pre_up output"), 0644)
+	os.WriteFile(filepath.Join(logged, "api.log"), []byte("listening"), 0644)
+
+	for _, dir := range []string{bookkeeping, logged, filepath.Join(base, "missing")} {
+		if err := RemoveRunDir(dir, "hooks.log", PaneEventsFile); err != nil {
+			t.Fatalf("RemoveRunDir(%s) error = %v", dir, err)
+		}
+	}
+	if _, err := os.Stat(bookkeeping); !os.IsNotExist(err) {
+		t.Error("directory with only bookkeeping and empty files should be removed")
+	}
+	if _, err := os.Stat(filepath.Join(logged, "hooks.log")); err != nil {
+		t.Error("directory with pane output should be kept whole")
+	}
+}
+
+func TestServer_Args(t *testing.T) {
+	tests := []struct {
+		server Server
+		want   string
+	}{
+		{Server{}, ""},
+		{Server{Config: "/home/me/.tmux.conf"}, "-f /home/me/.tmux.conf"},
+		{Server{Socket: "devlog-myapp"}, "-L devlog-myapp -f /dev/null"},
+		{Server{Socket: "devlog-myapp", Config: "./devlog.tmux.conf"}, "-L devlog-myapp -f ./devlog.tmux.conf"},
+		{Server{Socket: "/tmp/devlog.sock", Config: "/dev/null"}, "-S /tmp/devlog.sock -f /dev/null"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(tt.server.Args(), " "); got != tt.want {
+			t.Errorf("%+v.Args() = %q, want %q", tt.server, got, tt.want)
+		}
+	}
+}
+
+func TestRunner_CreateSession_OwnServer(t *testing.T) {
+	session := "test-own-server"
+	runner := NewServerRunner(session, testServer(t))
+	err := runner.CreateSession(SessionConfig{
+		Session: session,
+		RunDir:  t.TempDir(),
+		Windows: []config.WindowConfig{{Name: "main", Panes: []config.PaneConfig{{Cmd: "sleep 30"}}}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	if !runner.SessionExists() {
+		t.Error("SessionExists() = false on the session's own server")
+	}
+	if NewRunner(session).SessionExists() {
+		t.Error("session was created on the default tmux server")
+	}
+	if err := runner.KillSession(); err != nil {
+		t.Errorf("KillSession() error = %v", err)
+	}
+}
+
+func TestParseProcesses(t *testing.T) {
+	procs := parseProcesses("    1     0     1 Ss\n  200     1   200 S+\n  201   200   201 S\n  202   201   201 Z\n  300     1   300 S\nbad line\n")
+	if len(procs) != 5 {
+		t.Fatalf("parseProcesses() returned %d processes, want 5", len(procs))
+	}
+	if !procs[3].zombie || procs[2].zombie {
+		t.Errorf("zombie flags = %v, %v", procs[2].zombie, procs[3].zombie)
+	}
+
+	var pids []int
+	for _, p := range descendants(procs, 200) {
+		pids = append(pids, p.pid)
+	}
+	if fmt.Sprint(pids) != "[201 202]" {
+		t.Errorf("descendants(200) = %v, want [201 202]", pids)
+	}
+
+	target := &stopTarget{groups: map[int]bool{201: true}}
+	if !target.alive(procs) {
+		t.Error("alive() = false with a running process in the group")
+	}
+	if target.alive(procs[3:]) {
+		t.Error("alive() = true with only a zombie left in the group")
+	}
+}
+
+func TestRunner_StopSession_Escalates(t *testing.T) {
+	session := "test-stop-escalates"
+	runner := NewServerRunner(session, testServer(t))
+	// The duration tells this test's sleeps from those of other tests and
+	// concurrent runs
+	sleep := fmt.Sprintf("sleep %d", 100+os.Getpid())
+	dir := t.TempDir()
+	quick, stubborn := filepath.Join(dir, "quick"), filepath.Join(dir, "stubborn")
+	err := runner.CreateSession(SessionConfig{
+		Session: session,
+		RunDir:  dir,
+		Windows: []config.WindowConfig{{Name: "main", Panes: []config.PaneConfig{
+			{Name: "quick", Cmd: "touch " + quick + "; " + sleep},
+			// The marker is written once the trap is in place
+			{Name: "stubborn", Cmd: "trap '' INT TERM; touch " + stubborn + "; " + sleep},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	// Wait until both commands run, so the signals reach them
+	if !waitFor(paneStartTimeout, func() bool {
+		for _, marker := range []string{quick, stubborn} {
+			if _, err := os.Stat(marker); err != nil {
+				return false
+			}
+		}
+		out, _ := exec.Command("ps", "-A", "-o", "args=").Output()
+		running := 0
+		for _, line := range strings.Split(string(out), "\n") {
+			if line == sleep {
+				running++
+			}
+		}
+		return running >= 2
+	}) {
+		t.Fatalf("%s did not start in both panes", sleep)
+	}
+
+	stops, err := runner.StopSession(StopOptions{Signals: []string{"INT", "TERM", "KILL"}, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("StopSession() error = %v", err)
+	}
+	if len(stops) != 1 || stops[0].Pane != "stubborn" || !stops[0].Forced || stops[0].Signal != "KILL" {
+		t.Errorf("StopSession() = %+v, want stubborn force-killed with KILL", stops)
+	}
+	if runner.SessionExists() {
+		t.Error("session still exists after StopSession()")
+	}
+}
+
+func TestPipeCommand(t *testing.T) {
+	start := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	pane := config.PaneConfig{Log: "api.log"}
+	cfg := SessionConfig{Devlog: "/usr/bin/devlog"}
+	if got := pipeCommand(cfg, "/logs", pane, start); got != "cat >> '/logs/api.log'" {
+		t.Errorf("pipeCommand() = %q, want plain cat", got)
+	}
+
+	pane.Timestamps = config.TimestampsRelative
+	want := "'/usr/bin/devlog' pipe --timestamps 'relative' --start 2026-01-02T15:04:05Z '/logs/api.log'"
+	if got := pipeCommand(cfg, "/logs", pane, start); got != want {
+		t.Errorf("pipeCommand() = %q, want %q", got, want)
+	}
+
+	pane = config.PaneConfig{Log: "api.log", Sanitize: true, RawLog: "api.raw.log"}
+	want = "'/usr/bin/devlog' pipe --sanitize --raw '/logs/api.raw.log' '/logs/api.log'"
+	if got := pipeCommand(cfg, "/logs", pane, start); got != want {
+		t.Errorf("pipeCommand() = %q, want %q", got, want)
+	}
+
+	pane = config.PaneConfig{Log: "api.log", MaxFileSize: "1KB", MaxFiles: 2, Compress: true}
+	want = "'/usr/bin/devlog' pipe --max-file-size 1024 --max-files 2 --compress '/logs/api.log'"
+	if got := pipeCommand(cfg, "/logs", pane, start); got != want {
+		t.Errorf("pipeCommand() = %q, want %q", got, want)
+	}
+
+	cfg.Redact = config.RedactConfig{Presets: config.StringList{"email"}, Patterns: config.StringList{`pw='(\S+)`}}
+	want = `'/usr/bin/devlog' pipe --redact-preset 'email' --redact-pattern 'pw='\''(\S+)' '/logs/api.log'`
+	if got := pipeCommand(cfg, "/logs", config.PaneConfig{Log: "api.log"}, start); got != want {
+		t.Errorf("pipeCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestReadinessProbe_TimestampedLog(t *testing.T) {
+	logsDir := t.TempDir()
+	for _, timestamps := range []string{config.TimestampsRFC3339, config.TimestampsRelative} {
+		pane := config.PaneConfig{Log: "api.log", Timestamps: timestamps, Ready: config.ReadyConfig{Log: "^listening on :8080$"}}
+		f, err := os.Create(filepath.Join(logsDir, pane.Log))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The log as devlog pipe writes it for the pane
+		output := strings.NewReader("starting\nlistening on :8080\n")
+		if err := panelog.Copy(f, output, panelog.Options{Timestamps: timestamps, Start: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		p, err := readinessProbe(logsDir, paneSlot{pane: pane})
+		if err != nil {
+			t.Fatalf("readinessProbe() error = %v", err)
+		}
+		if err := p.Check(context.Background()); err != nil {
+			t.Errorf("%s timestamps: Check() error = %v, want the anchored pattern to match", timestamps, err)
+		}
+	}
+}
+
+func TestRunner_StopSession_KillsSessionWhenProcessesUnlisted(t *testing.T) {
+	runner := NewServerRunner("test-stop-no-ps", testServer(t))
+	if err := runner.CreateSession(SessionConfig{
+		RunDir:  t.TempDir(),
+		Windows: []config.WindowConfig{{Name: "main", Panes: []config.PaneConfig{{Cmd: "true"}}}},
+	}); err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+
+	// Leave only tmux on PATH so listing processes with ps fails
+	tmuxPath, _ := exec.LookPath("tmux")
+	bin := t.TempDir()
+	if err := os.Symlink(tmuxPath, filepath.Join(bin, "tmux")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	_, err := runner.StopSession(StopOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to get pane processes") {
+		t.Errorf("StopSession() error = %v, want ps failure", err)
+	}
+	if runner.SessionExists() {
+		t.Error("session should be killed even when its processes can't be listed")
+	}
+}